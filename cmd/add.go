@@ -5,7 +5,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/roveo/wt/internal/config"
 	"github.com/roveo/wt/internal/db"
@@ -14,20 +13,32 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	addBase string
+)
+
 var addCmd = &cobra.Command{
 	Use:   "add [branch]",
 	Short: "Add a new worktree",
 	Long: `Add a new worktree for the current repository.
 
-If branch is not specified, an interactive picker will be shown
-to select from available remote branches, or you can enter a new branch name.
+If branch is not specified, an interactive picker will be shown to select
+from local and remote branches, or you can enter a new branch name.
+Press ctrl-f in the picker to fetch from all remotes first.
 
-The worktree will be created at ../{repo}.worktrees/{branch}`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runAdd,
+New branches are created from --base, default_base in .wt.toml, or the
+remote default branch (refs/remotes/origin/HEAD), in that order.
+
+The worktree location comes from worktrees_dir in .wt.toml or the global
+config, falling back to ../{repo}.worktrees/{branch}.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeBranches,
+	RunE:              runAdd,
 }
 
 func init() {
+	addCmd.Flags().StringVarP(&addBase, "base", "b", "", "Ref to create a new branch from (e.g. origin/main)")
+	_ = addCmd.RegisterFlagCompletionFunc("base", completeRefs)
 	rootCmd.AddCommand(addCmd)
 }
 
@@ -51,7 +62,12 @@ func runAdd(cmd *cobra.Command, args []string) error {
 
 	// If branch provided as argument, use it directly
 	if len(args) > 0 {
-		return runAddWithBranchFromRepo(mainRepoPath, args[0])
+		branch := args[0]
+		base := addBase
+		if base == "" {
+			base = defaultBaseForBranch(mainRepoPath, branch)
+		}
+		return runAddWithBranchFromRepo(mainRepoPath, branch, base)
 	}
 
 	// Otherwise, run interactive workflow using a synthetic worktree for current repo
@@ -91,24 +107,80 @@ func runAddWorkflow(sourceWorktree *db.Worktree) (ui.PickerAction, error) {
 		return ui.ActionNone, fmt.Errorf("no source worktree selected")
 	}
 
-	// Show interactive picker for branch name
-	branch, action, err := ui.InputBranch("feature/my-branch", sourceWorktree.RepoName, sourceWorktree.Branch)
+	if _, err := loadConfig(); err != nil {
+		return ui.ActionNone, err
+	}
+
+	// Show interactive picker for local/remote branches or a new branch name
+	base := addBase
+	if base == "" {
+		base = defaultBase(sourceWorktree.RepoPath)
+	}
+	choice, action, err := ui.PickBranch(sourceWorktree.RepoPath, sourceWorktree.RepoName, base)
 	if err != nil {
 		return ui.ActionNone, err
 	}
 	if action == ui.ActionBack {
 		return ui.ActionBack, nil
 	}
-	if branch == "" {
+	if choice == nil || choice.Name == "" {
 		// User cancelled
 		return ui.ActionNone, nil
 	}
 
-	return ui.ActionNone, runAddWithBranchFromRepo(sourceWorktree.RepoPath, branch)
+	// Branch is already checked out - switch to its worktree instead
+	if choice.WorktreePath != "" {
+		return ui.ActionNone, switchToPath(sourceWorktree, choice.WorktreePath, choice.Name)
+	}
+
+	return ui.ActionNone, runAddWithBranchFromRepo(sourceWorktree.RepoPath, choice.Name, choice.Base)
+}
+
+// defaultBase returns the ref new branches are created from when no --base
+// is given: default_base from .wt.toml, else the remote default branch.
+// Returns "" (HEAD) if neither is available.
+func defaultBase(repoPath string) string {
+	projectCfg, _ := config.LoadProject(repoPath)
+	if projectCfg.DefaultBase != "" {
+		return projectCfg.DefaultBase
+	}
+	if ref, err := git.DefaultBranchRef(repoPath); err == nil {
+		return ref
+	}
+	return ""
 }
 
-// runAddWithBranchFromRepo creates a worktree for the given branch from the specified repo
-func runAddWithBranchFromRepo(repoPath, branch string) error {
+// defaultBaseForBranch is like defaultBase, but prefers origin/<branch> so that
+// an existing remote branch is checked out with tracking instead of recreated
+func defaultBaseForBranch(repoPath, branch string) string {
+	if git.RefExists(repoPath, "refs/remotes/origin/"+branch) {
+		return "origin/" + branch
+	}
+	return defaultBase(repoPath)
+}
+
+// switchToPath switches to an existing worktree of the source repo
+func switchToPath(source *db.Worktree, path, branch string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	wt, err := db.GetWorktreeByPath(database, path)
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if wt == nil {
+		// Not synced yet, switch without a database entry
+		wt = &db.Worktree{Path: path, Branch: branch, RepoPath: source.RepoPath, RepoName: source.RepoName}
+	}
+	fmt.Fprintf(os.Stderr, "Branch '%s' is already checked out at %s\n", branch, path)
+	outputWorktreeSwitch(wt)
+	return nil
+}
+
+// runAddWithBranchFromRepo creates a worktree for the given branch from the specified repo.
+// If the branch doesn't exist locally, it is created from base (HEAD if empty).
+func runAddWithBranchFromRepo(repoPath, branch, base string) error {
 	// Open database and ensure repo is indexed
 	database, err := db.Default()
 	if err != nil {
@@ -119,34 +191,43 @@ func runAddWithBranchFromRepo(repoPath, branch string) error {
 		return err
 	}
 
-	// Sanitize branch name for directory (replace / with -)
-	dirName := strings.ReplaceAll(branch, "/", "-")
+	repo, err := db.GetRepoByPath(database, repoPath)
+	if err != nil || repo == nil {
+		return fmt.Errorf("failed to get repo from database: %w", err)
+	}
 
 	// Determine target path
-	worktreesDir := git.GetDefaultWorktreesDir(repoPath)
-	targetPath := filepath.Join(worktreesDir, dirName)
+	targetPath, err := resolveWorktreePath(repo, branch)
+	if err != nil {
+		return err
+	}
 
 	// Check if target already exists
 	if _, err := os.Stat(targetPath); err == nil {
 		return fmt.Errorf("worktree directory already exists: %s", targetPath)
 	}
 
-	// Ensure worktrees directory exists
-	if err := os.MkdirAll(worktreesDir, 0755); err != nil {
+	// Ensure parent directory exists
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
 		return fmt.Errorf("failed to create worktrees directory: %w", err)
 	}
 
 	// Create worktree
 	fmt.Fprintf(os.Stderr, "Creating worktree for branch '%s' at %s...\n", branch, targetPath)
-	if err := git.AddWorktree(repoPath, branch, targetPath); err != nil {
+	if err := git.AddWorktree(repoPath, branch, base, targetPath); err != nil {
+		switch {
+		case git.IsKind(err, git.ErrBranchCheckedOut):
+			// Checked out since the picker loaded, or passed as an argument
+			if path := checkedOutPath(repoPath, branch); path != "" {
+				return switchToPath(&db.Worktree{RepoPath: repoPath, RepoName: repo.Name}, path, branch)
+			}
+		case git.IsKind(err, git.ErrInvalidRef) && base != "" && !git.RefExists(repoPath, base):
+			return fmt.Errorf("base ref '%s' not found (run 'git fetch' or choose another with --base)", base)
+		}
 		return fmt.Errorf("failed to create worktree: %w", err)
 	}
 
 	// Sync to update database
-	repo, err := db.GetRepoByPath(database, repoPath)
-	if err != nil || repo == nil {
-		return fmt.Errorf("failed to get repo from database: %w", err)
-	}
 	if err := syncWorktrees(database, repo); err != nil {
 		return fmt.Errorf("failed to sync worktrees: %w", err)
 	}
@@ -172,6 +253,56 @@ func runAddWithBranchFromRepo(repoPath, branch string) error {
 	return nil
 }
 
+// checkedOutPath returns the worktree that has branch checked out, or ""
+func checkedOutPath(repoPath, branch string) string {
+	worktrees, err := git.ListWorktrees(repoPath)
+	if err != nil {
+		return ""
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			return wt.Path
+		}
+	}
+	return ""
+}
+
+// resolveWorktreePath returns the path for a new worktree of branch in repo,
+// expanding the worktrees_dir template from .wt.toml or the global config
+func resolveWorktreePath(repo *db.Repo, branch string) (string, error) {
+	globalCfg, err := config.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	projectCfg, err := config.LoadProject(repo.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to load .wt.toml: %w", err)
+	}
+
+	repoDefault := repo.WorktreesDir
+	if repoDefault == "" {
+		repoDefault = git.GetDefaultWorktreesDir(repo.Path)
+	}
+	tmpl := config.ResolveWorktreesTemplate(projectCfg, globalCfg, repoDefault)
+
+	home, _ := os.UserHomeDir()
+	vars := config.TemplateVars{
+		RepoName: repo.Name,
+		RepoPath: repo.Path,
+		Branch:   branch,
+		User:     config.CurrentUser(),
+		Home:     home,
+	}
+	// Only shell out to git when the template actually needs the remote
+	if config.TemplateUses(tmpl, "remote_owner") {
+		if url, err := git.GetRemoteURL(repo.Path, "origin"); err == nil {
+			vars.RemoteOwner = git.ParseRemoteOwner(url)
+		}
+	}
+
+	return config.ExpandTemplate(tmpl, vars)
+}
+
 // runSetupCommands runs the setup commands in the given directory
 func runSetupCommands(dir string, commands []string) error {
 	for _, cmdStr := range commands {
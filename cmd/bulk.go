@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/roveo/wt/internal/config"
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/proc"
+	"github.com/roveo/wt/internal/shell"
+	"github.com/roveo/wt/internal/tmux"
+	"github.com/roveo/wt/internal/ui"
+)
+
+// deleteWorktrees deletes several worktrees after one combined confirmation
+// that lists them all with their uncommitted files, unpushed commits and
+// stashes, and offers to delete their branches. Worktrees in use are
+// skipped. If any uncommitted changes or unpushed commits would be lost,
+// confirming takes typing "delete".
+func deleteWorktrees(database *sql.DB, worktrees []*db.Worktree, cfg config.RemoveConfig) error {
+	var targets []*db.Worktree
+	checks := make(map[string]*git.RemovalCheck)
+	plans := make(map[string]*branchPlan)
+
+	fmt.Fprintf(os.Stderr, "The following worktrees will be deleted:\n")
+	for _, wt := range worktrees {
+		name := wt.RepoName + "/" + wt.Branch
+		if wt.IsMain {
+			fmt.Fprintf(os.Stderr, "  %s  (main worktree, skipped)\n", name)
+			continue
+		}
+		if procs := processesInWorktree(wt); len(procs) > 0 {
+			fmt.Fprintf(os.Stderr, "  %s  (in use by %s, skipped)\n", name, proc.Describe(procs))
+			continue
+		}
+		check, err := checkRemoval(wt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s  (skipped: %v)\n", name, err)
+			continue
+		}
+		plan := planBranch(wt)
+		targets = append(targets, wt)
+		checks[wt.Path] = check
+		plans[wt.Path] = plan
+		fmt.Fprintf(os.Stderr, "  %s\n", name)
+		printRemovalCheck(check, plan.keep != "", "    ")
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("no worktrees to delete")
+	}
+
+	title := fmt.Sprintf("Delete %d worktree(s)?", len(targets))
+	var deletable []*branchPlan
+	for _, wt := range targets {
+		if plans[wt.Path].keep == "" {
+			deletable = append(deletable, plans[wt.Path])
+		}
+	}
+	if len(deletable) > 0 {
+		// The branch options double as the confirmation
+		confirmed, err := pickBranchDeletion(title, deletable, cfg)
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	destructive := false
+	for _, wt := range targets {
+		check, plan := checks[wt.Path], plans[wt.Path]
+		if plan.destructive(check) {
+			fmt.Fprintf(os.Stderr, "%s/%s: ", wt.RepoName, wt.Branch)
+			printBranchPlan(plan, "")
+		}
+		destructive = destructive || check.Destructive() || plan.destructive(check)
+	}
+	if destructive || len(deletable) == 0 {
+		confirmed, err := confirmRemoval(title, destructive, "delete")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	failed := 0
+	for _, wt := range targets {
+		fmt.Fprintf(os.Stderr, "Removing %s/%s...\n", wt.RepoName, wt.Branch)
+		if err := removeTrackedWorktree(database, wt, forceRemoval(checks[wt.Path]), snapshotAsk); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+			continue
+		}
+		deleteWorktreeBranch(wt.RepoPath, plans[wt.Path])
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d worktree(s)", failed, len(targets))
+	}
+
+	fmt.Fprintf(os.Stderr, "Deleted %d worktree(s).\n", len(targets))
+	return nil
+}
+
+// runInWorktrees prompts for a shell command and runs it in each worktree
+// in turn, continuing past failures
+func runInWorktrees(worktrees []*db.Worktree) error {
+	command, ok, err := ui.InputText(fmt.Sprintf("Command to run in %d worktree(s):", len(worktrees)), "")
+	if err != nil {
+		return err
+	}
+	if !ok || command == "" {
+		return nil
+	}
+
+	failed := 0
+	for _, wt := range worktrees {
+		fmt.Fprintf(os.Stderr, "==> %s/%s\n", wt.RepoName, wt.Branch)
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Dir = wt.Path
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("command failed in %d of %d worktree(s)", failed, len(worktrees))
+	}
+	return nil
+}
+
+// openTmuxWindows opens a tmux window for each worktree, regardless of the
+// configured tmux mode, and switches to the first one
+func openTmuxWindows(worktrees []*db.Worktree) error {
+	globalCfg, _ := config.Load()
+	session := globalCfg.Tmux.Session
+	switch {
+	case session != "":
+		if !tmux.SessionExists(session) {
+			if err := tmux.CreateSession(session); err != nil {
+				return fmt.Errorf("failed to create tmux session: %w", err)
+			}
+		}
+	case tmux.InTmux():
+		session = tmux.CurrentSession()
+	default:
+		return fmt.Errorf("opening tmux windows needs tmux: run wt inside tmux or set [tmux] session in the config")
+	}
+
+	opened := 0
+	for _, wt := range worktrees {
+		windowName := fmt.Sprintf("%s:%s", wt.RepoName, wt.Branch)
+		if tmux.WindowExists(session, windowName) {
+			continue
+		}
+		projectCfg, _ := config.LoadProject(wt.RepoPath)
+		if err := tmux.CreateWindow(session, windowName, wt.Path, projectCfg.OnEnter); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to create tmux window '%s': %v\n", windowName, err)
+			continue
+		}
+		opened++
+	}
+	fmt.Fprintf(os.Stderr, "Opened %d tmux window(s).\n", opened)
+
+	first := worktrees[0]
+	recordAccess(first)
+	if err := tmux.SwitchToWindow(session, fmt.Sprintf("%s:%s", first.RepoName, first.Branch)); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to switch tmux window: %v\n", err)
+	}
+
+	if !tmux.InTmux() {
+		d := shell.NewDirectives()
+		d.Attach(session)
+		flushDirectives(d)
+	} else if tmux.CurrentSession() != session {
+		if err := tmux.SwitchClient(session); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to switch tmux session: %v\n", err)
+		}
+	}
+	return nil
+}
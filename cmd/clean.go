@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanAll          bool
+	cleanOlderThan    int
+	cleanDeleteBranch bool
+	cleanForce        bool
+	cleanDryRun       bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Remove worktrees whose branches are merged or gone",
+	Long: `Find worktrees that are safe to clean up and remove the ones you select.
+
+A worktree is a candidate if its branch:
+  - is merged into the default branch (including squash merges)
+  - has an upstream branch that was deleted on the remote ([gone])
+  - has no commits for more than --older-than days
+
+Only the current repository is checked unless --all is given.
+Worktrees with uncommitted changes are never removed without --force.`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().BoolVarP(&cleanAll, "all", "a", false, "Check all tracked repositories")
+	cleanCmd.Flags().IntVar(&cleanOlderThan, "older-than", 0, "Also clean branches with no commits in this many days")
+	cleanCmd.Flags().BoolVarP(&cleanDeleteBranch, "delete-branch", "d", false, "Also delete the local branch")
+	cleanCmd.Flags().BoolVarP(&cleanForce, "force", "f", false, "Allow removing worktrees with uncommitted changes")
+	cleanCmd.Flags().BoolVarP(&cleanDryRun, "dry-run", "n", false, "Only list candidates, don't remove anything")
+	rootCmd.AddCommand(cleanCmd)
+}
+
+// cleanCandidate is a worktree that may be cleaned up
+type cleanCandidate struct {
+	worktree *db.Worktree
+	reasons  []string
+	merged   bool // branch changes are in the default branch, safe to force-delete
+	dirty    bool
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	// Sync phase: ensure current repo is in DB, then sync all repos
+	inRepo := git.IsInsideRepo(cwd)
+	if inRepo {
+		if err := ensureCurrentRepoInDB(database, cwd); err != nil {
+			return err
+		}
+	}
+	if err := syncAllRepos(database); err != nil {
+		return err
+	}
+
+	var repos []*db.Repo
+	if cleanAll {
+		repos, err = db.ListRepos(database)
+		if err != nil {
+			return fmt.Errorf("failed to list repos: %w", err)
+		}
+	} else {
+		if !inRepo {
+			return fmt.Errorf("not inside a git repository (use --all to check all tracked repos)")
+		}
+		mainRepoPath, err := git.GetMainRepoPath(cwd)
+		if err != nil {
+			return fmt.Errorf("failed to get main repo path: %w", err)
+		}
+		repo, err := db.GetRepoByPath(database, mainRepoPath)
+		if err != nil || repo == nil {
+			return fmt.Errorf("failed to get repo from database: %w", err)
+		}
+		repos = []*db.Repo{repo}
+	}
+
+	var candidates []*cleanCandidate
+	for _, repo := range repos {
+		found, err := findCleanCandidates(database, repo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to check %s: %v\n", repo.Name, err)
+			continue
+		}
+		candidates = append(candidates, found...)
+	}
+
+	if len(candidates) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to clean.")
+		return nil
+	}
+
+	if cleanDryRun {
+		for _, c := range candidates {
+			fmt.Printf("%s/%s\t%s\t%s\n", c.worktree.RepoName, c.worktree.Branch, c.worktree.Path, candidateDetail(c))
+		}
+		return nil
+	}
+
+	items := make([]ui.SelectItem, len(candidates))
+	for i, c := range candidates {
+		blocked := c.dirty && !cleanForce
+		items[i] = ui.SelectItem{
+			Label:    fmt.Sprintf("%s/%s", c.worktree.RepoName, c.worktree.Branch),
+			Detail:   candidateDetail(c),
+			Selected: !c.dirty,
+			Disabled: blocked,
+		}
+	}
+
+	selected, err := ui.MultiSelect("Select worktrees to remove:", items)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		fmt.Fprintln(os.Stderr, "Cancelled.")
+		return nil
+	}
+
+	policy := snapshotRequired
+	if cleanForce {
+		policy = snapshotOptional
+	}
+	removed := 0
+	for _, i := range selected {
+		c := candidates[i]
+		wt := c.worktree
+		fmt.Fprintf(os.Stderr, "Removing %s/%s...\n", wt.RepoName, wt.Branch)
+		if err := removeTrackedWorktree(database, wt, cleanForce, policy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		removed++
+
+		if cleanDeleteBranch && wt.Branch != "(detached)" {
+			// Squash-merged branches are not ancestors of the default branch,
+			// so only force-delete when we know the changes landed
+			if err := git.DeleteBranch(wt.RepoPath, wt.Branch, c.merged); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to delete branch %s: %v\n", wt.Branch, err)
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Removed %d worktree(s).\n", removed)
+	return nil
+}
+
+// findCleanCandidates returns the worktrees of repo that can be cleaned up
+func findCleanCandidates(database *sql.DB, repo *db.Repo) ([]*cleanCandidate, error) {
+	worktrees, err := db.ListWorktreesByRepo(database, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	base, baseErr := git.DefaultBranchRef(repo.Path)
+	baseBranch := strings.TrimPrefix(base, "origin/")
+	gone, err := git.ListGoneBranches(repo.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*cleanCandidate
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			continue
+		}
+
+		c := &cleanCandidate{worktree: wt}
+		detached := wt.Branch == "(detached)"
+
+		// A branch without commits of its own is contained in the default
+		// branch too, but was never merged
+		if !detached && baseErr == nil && wt.Branch != baseBranch && git.HasOwnCommits(repo.Path, wt.Branch) {
+			if merged, err := git.IsMerged(repo.Path, wt.Branch, base); err == nil && merged {
+				c.merged = true
+				c.reasons = append(c.reasons, "merged into "+base)
+			} else if squashed, err := git.IsSquashMerged(repo.Path, wt.Branch, base); err == nil && squashed {
+				c.merged = true
+				c.reasons = append(c.reasons, "squash-merged into "+base)
+			}
+		}
+
+		if !detached && gone[wt.Branch] {
+			c.reasons = append(c.reasons, "upstream gone")
+		}
+
+		if cleanOlderThan > 0 {
+			ref := wt.Branch
+			if detached {
+				ref = "HEAD"
+			}
+			if last, err := git.LastCommitTime(wt.Path, ref); err == nil {
+				age := time.Since(last)
+				if age > time.Duration(cleanOlderThan)*24*time.Hour {
+					c.reasons = append(c.reasons, fmt.Sprintf("no commits for %d days", int(age.Hours()/24)))
+				}
+			}
+		}
+
+		if len(c.reasons) == 0 {
+			continue
+		}
+
+		if status, err := git.GetWorktreeStatus(wt.Path); err == nil {
+			c.dirty = status.IsDirty()
+		}
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+}
+
+// candidateDetail describes why a worktree is a clean candidate
+func candidateDetail(c *cleanCandidate) string {
+	detail := strings.Join(c.reasons, ", ")
+	if c.dirty {
+		if cleanForce {
+			detail += " [dirty]"
+		} else {
+			detail += " [dirty, needs --force]"
+		}
+	}
+	return detail
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// Completion functions read the database without syncing, so they stay fast
+// enough to run on every <TAB>.
+
+// completeWorktrees completes removable worktrees as paths and repo/branch names
+func completeWorktrees(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	database, err := db.Default()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	worktrees, err := db.ListAllWorktrees(database)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var candidates []string
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			continue
+		}
+		candidates = append(candidates,
+			fmt.Sprintf("%s/%s\t%s", wt.RepoName, wt.Branch, wt.Path),
+			fmt.Sprintf("%s\t%s/%s", wt.Path, wt.RepoName, wt.Branch),
+		)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSwitch completes repo/branch names of all worktrees
+func completeSwitch(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	database, err := db.Default()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	worktrees, err := db.ListAllWorktrees(database)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var candidates []string
+	for _, wt := range worktrees {
+		candidates = append(candidates, fmt.Sprintf("%s/%s\t%s", wt.RepoName, wt.Branch, wt.Path))
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBranches completes local and remote branches of the current repo
+func completeBranches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeRefs(cmd, args, toComplete)
+}
+
+// completeRefs completes local and remote branch refs of the current repo
+func completeRefs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	branches, err := git.ListBranches(cwd)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var candidates []string
+	for _, b := range branches {
+		desc := "local"
+		if b.Remote != "" {
+			desc = "remote"
+		}
+		candidates = append(candidates, b.Ref()+"\t"+desc)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepoNames completes the names of tracked repos
+func completeRepoNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	database, err := db.Default()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	repos, err := db.ListRepos(database)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var candidates []string
+	for _, repo := range repos {
+		candidates = append(candidates, repo.Name+"\t"+repo.Path)
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerRepoFlagCompletions completes repo names for every --repo flag
+// in the command tree, so repo-scoped flags don't need their own wiring
+func registerRepoFlagCompletions(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("repo") != nil {
+		// Only fails if the flag is missing or already registered
+		_ = cmd.RegisterFlagCompletionFunc("repo", completeRepoNames)
+	}
+	for _, sub := range cmd.Commands() {
+		registerRepoFlagCompletions(sub)
+	}
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcAll          bool
+	gcPruneMissing bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Purge removed worktrees and repos from the database",
+	Long: `Purge removed worktrees and repos from the database and compact it.
+
+wt keeps worktrees and repos it no longer sees, with their switch history,
+for retention_days (30 by default) and purges older ones on every sync.
+'wt gc' purges them right away, deletes snapshots of removed worktrees
+older than retention_days (see 'wt restore') and vacuums the database file.
+With --all, removed rows and snapshots are purged regardless of age.
+
+Repos whose directory is gone from disk are marked missing. They stay
+tracked, so a repo on an unmounted drive isn't lost, until they are pruned
+with --prune-missing. A repo that was moved can be updated with
+'wt repo relocate' instead.`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().BoolVar(&gcAll, "all", false, "Purge all removed rows, regardless of retention_days")
+	gcCmd.Flags().BoolVar(&gcPruneMissing, "prune-missing", false, "Delete repos whose directory no longer exists")
+	rootCmd.AddCommand(gcCmd)
+}
+
+func runGC(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	dbPath, err := db.DefaultPath()
+	if err != nil {
+		return fmt.Errorf("failed to get database path: %w", err)
+	}
+	sizeBefore := fileSize(dbPath)
+
+	// Check every repo rather than relying on the last sync
+	repos, err := db.ListRepos(database)
+	if err != nil {
+		return fmt.Errorf("failed to list repos: %w", err)
+	}
+	var missing []*db.Repo
+	for _, repo := range repos {
+		_, err := os.Stat(repo.Path)
+		isMissing := os.IsNotExist(err)
+		if isMissing != (repo.MissingAt != nil) {
+			if err := db.SetRepoMissing(database, repo.ID, isMissing); err != nil {
+				return fmt.Errorf("failed to update repo: %w", err)
+			}
+		}
+		if isMissing {
+			missing = append(missing, repo)
+		}
+	}
+
+	if gcAll || cfg.RetentionDays > 0 {
+		days := cfg.RetentionDays
+		if gcAll {
+			days = 0
+		}
+		// Before purging repos, which takes their snapshot records along
+		snapshots, err := purgeSnapshots(database, days)
+		if err != nil {
+			return err
+		}
+		if err := discardPurgeableSnapshots(database, days); err != nil {
+			return err
+		}
+		if snapshots > 0 {
+			fmt.Fprintf(os.Stderr, "Deleted %d snapshot(s).\n", snapshots)
+		}
+
+		worktrees, purgedRepos, err := db.PurgeDeleted(database, days)
+		if err != nil {
+			return fmt.Errorf("failed to purge removed rows: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Purged %d removed worktree(s) and %d removed repo(s).\n", worktrees, purgedRepos)
+	}
+
+	for _, repo := range missing {
+		if !gcPruneMissing {
+			fmt.Fprintf(os.Stderr, "Missing: %s (%s)\n", repo.Name, repo.Path)
+			continue
+		}
+		if err := discardRepoSnapshots(database, repo.ID); err != nil {
+			return err
+		}
+		if err := db.DeleteRepo(database, repo.ID); err != nil {
+			return fmt.Errorf("failed to prune repo: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Pruned missing repo %s (%s)\n", repo.Name, repo.Path)
+	}
+	if len(missing) > 0 && !gcPruneMissing {
+		fmt.Fprintf(os.Stderr, "Run 'wt gc --prune-missing' to delete missing repos, or 'wt repo relocate' if they moved.\n")
+	}
+
+	if err := db.Vacuum(database); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Database: %s -> %s\n", formatSize(sizeBefore), formatSize(fileSize(dbPath)))
+	return nil
+}
+
+// purgeSnapshots deletes snapshots taken more than days ago, or all of them
+// if days is 0, along with their refs and patches
+func purgeSnapshots(database *sql.DB, days int) (int, error) {
+	snapshots, err := db.ListSnapshots(database)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+	purged := 0
+	for _, snap := range snapshots {
+		if snap.CreatedAt.After(cutoff) {
+			continue
+		}
+		if err := discardSnapshot(database, snap); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to delete snapshot %d: %v\n", snap.ID, err)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// discardPurgeableSnapshots deletes the snapshots of the repos that
+// PurgeDeleted is about to delete with days
+func discardPurgeableSnapshots(database *sql.DB, days int) error {
+	ids, err := db.ListPurgeableRepos(database, days)
+	if err != nil {
+		return fmt.Errorf("failed to list removed repos: %w", err)
+	}
+	return discardRepoSnapshots(database, ids...)
+}
+
+// discardRepoSnapshots deletes the snapshots of repos about to be deleted for
+// good, with their refs and patches. Deleting a repo row takes its snapshot
+// rows along, but would leave the refs pinning objects in the repository.
+func discardRepoSnapshots(database *sql.DB, repoIDs ...int64) error {
+	if len(repoIDs) == 0 {
+		return nil
+	}
+	snapshots, err := db.ListSnapshots(database)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	for _, snap := range snapshots {
+		if !slices.Contains(repoIDs, snap.RepoID) {
+			continue
+		}
+		if err := discardSnapshot(database, snap); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to delete snapshot %d: %v\n", snap.ID, err)
+		}
+	}
+	return nil
+}
+
+// fileSize returns the size of a file, or 0 if it can't be read
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// formatSize formats a byte count in KB or MB
+func formatSize(n int64) string {
+	if n >= 1<<20 {
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	}
+	return fmt.Sprintf("%d KB", (n+1023)/1024)
+}
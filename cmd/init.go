@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/roveo/wt/internal/shell"
 	"github.com/spf13/cobra"
@@ -13,23 +14,56 @@ var initCmd = &cobra.Command{
 	Long: `Print the shell initialization script for wt.
 
 Add this to your shell's rc file:
-  bash: eval "$(wt init bash)"   # add to ~/.bashrc
-  zsh:  eval "$(wt init zsh)"    # add to ~/.zshrc
-  fish: wt init fish | source    # add to ~/.config/fish/config.fish
+  bash:   eval "$(wt init bash)"                          # ~/.bashrc
+  zsh:    eval "$(wt init zsh)"                           # ~/.zshrc
+  fish:   wt init fish | source                           # ~/.config/fish/config.fish
+  pwsh:   Invoke-Expression (& wt init pwsh | Out-String) # $PROFILE
+  elvish: eval (wt init elvish | slurp)                   # rc.elv
+  xonsh:  execx($(wt init xonsh))                         # ~/.xonshrc
+
+Nushell can't evaluate generated code, so save the script once and source it
+from config.nu:
+  wt init nu | save -f ~/.config/nushell/wt.nu
+  source ~/.config/nushell/wt.nu
 
 This creates a shell wrapper function that allows wt to change
-the current directory when switching worktrees.`,
-	Args: cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		script, err := shell.GetInit(args[0])
-		if err != nil {
-			return err
-		}
-		fmt.Print(script)
-		return nil
-	},
+the current directory when switching worktrees.
+
+With --completion the script also sets up tab completion (bash, zsh, fish
+and pwsh). Completion scripts alone are printed by 'wt completion <shell>'.`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"bash", "zsh", "fish", "nu", "pwsh", "elvish", "xonsh"},
+	RunE:      runInit,
 }
 
+var initCompletion bool
+
 func init() {
+	initCmd.Flags().BoolVar(&initCompletion, "completion", false, "Include the shell completion script")
 	rootCmd.AddCommand(initCmd)
 }
+
+func runInit(cmd *cobra.Command, args []string) error {
+	script, err := shell.GetInit(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(script)
+
+	if !initCompletion {
+		return nil
+	}
+	switch args[0] {
+	case "bash":
+		return rootCmd.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		return rootCmd.GenFishCompletion(os.Stdout, true)
+	case "pwsh", "powershell":
+		return rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "warning: completion is not available for %s\n", args[0])
+	}
+	return nil
+}
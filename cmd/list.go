@@ -1,26 +1,74 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"text/tabwriter"
+	"text/template"
+	"text/template/parse"
+	"time"
 
+	"github.com/roveo/wt/internal/config"
 	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
 	"github.com/spf13/cobra"
 )
 
+var (
+	listSort   string
+	listFormat string
+)
+
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List all tracked worktrees",
-	Long:    `List all worktrees tracked by wt across all repositories.`,
-	RunE:    runList,
+	Long: `List all worktrees tracked by wt across all repositories.
+
+--format selects the output:
+  table   aligned columns for humans (default)
+  json    an array of objects with every worktree field and live git status
+  tsv     tab-separated values with a header row, same fields as json
+  {{...}} a Go template executed for each worktree, e.g.
+          --format '{{.Repo}} {{.Path}} {{if .Dirty}}dirty{{end}}'
+
+Template fields are named like the JSON keys in CamelCase (Repo, Branch,
+Path, IsMain, Frecency, Dirty, Status.Ahead, ...).`,
+	Args: cobra.NoArgs,
+	RunE: runList,
 }
 
 func init() {
+	listCmd.Flags().StringVarP(&listSort, "sort", "s", "", "Sort order: name, frecency or recent (default from config)")
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", "table", "Output format: table, json, tsv or a Go template")
+	_ = listCmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(
+		[]string{"table", "json", "tsv"}, cobra.ShellCompDirectiveNoFileComp))
 	rootCmd.AddCommand(listCmd)
 }
 
+// listEntry is a worktree as emitted by the machine-readable list formats
+type listEntry struct {
+	ID             int64               `json:"id"`
+	RepoID         int64               `json:"repo_id"`
+	Repo           string              `json:"repo"`
+	RepoPath       string              `json:"repo_path"`
+	Branch         string              `json:"branch"`
+	Path           string              `json:"path"`
+	IsMain         bool                `json:"is_main"`
+	CreatedAt      time.Time           `json:"created_at"`
+	DeletedAt      *time.Time          `json:"deleted_at"`
+	AccessCount    int                 `json:"access_count"`
+	LastAccessedAt *time.Time          `json:"last_accessed_at"`
+	Frecency       float64             `json:"frecency"`
+	Dirty          bool                `json:"dirty"`
+	Status         *git.WorktreeStatus `json:"status"` // nil if git status failed
+}
+
 func runList(cmd *cobra.Command, args []string) error {
 	database, err := db.Default()
 	if err != nil {
@@ -32,6 +80,37 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
+	sortName := listSort
+	if sortName == "" {
+		globalCfg, _ := config.Load()
+		sortName = globalCfg.Sort
+	}
+	sortMode, err := db.ParseSortMode(sortName)
+	if err != nil {
+		return err
+	}
+	db.SortWorktrees(worktrees, sortMode, "")
+
+	switch {
+	case listFormat == "table":
+		return printTable(worktrees)
+	case listFormat == "json":
+		return printJSON(os.Stdout, listEntries(worktrees, true))
+	case listFormat == "tsv":
+		return printTSV(os.Stdout, listEntries(worktrees, true))
+	case strings.Contains(listFormat, "{{"):
+		tmpl, err := template.New("format").Parse(listFormat)
+		if err != nil {
+			return fmt.Errorf("invalid --format template: %w", err)
+		}
+		// Only pay for git status when the template uses it
+		return printTemplate(os.Stdout, tmpl, listEntries(worktrees, usesStatus(tmpl)))
+	default:
+		return fmt.Errorf("invalid --format %q (use table, json, tsv or a Go template)", listFormat)
+	}
+}
+
+func printTable(worktrees []*db.Worktree) error {
 	if len(worktrees) == 0 {
 		fmt.Println("No worktrees tracked. Run 'wt' inside a git repository to index it.")
 		return nil
@@ -46,7 +125,168 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Fprintf(w, "%s\t%s\t%s\n", wt.RepoName, branch, wt.Path)
 	}
-	w.Flush()
+	return w.Flush()
+}
+
+func printJSON(w io.Writer, entries []listEntry) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func printTSV(w io.Writer, entries []listEntry) error {
+	fmt.Fprintln(w, strings.Join([]string{
+		"repo", "branch", "path", "is_main", "id", "repo_id", "repo_path", "created_at",
+		"access_count", "last_accessed_at", "frecency", "dirty",
+		"staged", "modified", "untracked", "conflicts", "upstream", "ahead", "behind", "stashes",
+	}, "\t"))
+
+	for _, e := range entries {
+		lastAccessed := ""
+		if e.LastAccessedAt != nil {
+			lastAccessed = e.LastAccessedAt.Format(time.RFC3339)
+		}
+		status := e.Status
+		if status == nil {
+			status = &git.WorktreeStatus{}
+		}
+		fields := []string{
+			e.Repo, e.Branch, e.Path, strconv.FormatBool(e.IsMain),
+			strconv.FormatInt(e.ID, 10), strconv.FormatInt(e.RepoID, 10), e.RepoPath,
+			e.CreatedAt.Format(time.RFC3339),
+			strconv.Itoa(e.AccessCount), lastAccessed,
+			strconv.FormatFloat(e.Frecency, 'f', -1, 64), strconv.FormatBool(e.Dirty),
+			strconv.Itoa(status.Staged), strconv.Itoa(status.Modified),
+			strconv.Itoa(status.Untracked), strconv.Itoa(status.Conflicts),
+			status.Upstream, strconv.Itoa(status.Ahead), strconv.Itoa(status.Behind),
+			strconv.Itoa(status.Stashes),
+		}
+		// Tabs and newlines would break the columns
+		for i, f := range fields {
+			fields[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(f)
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	return nil
+}
 
+// printTemplate executes tmpl for each entry, one per line
+func printTemplate(w io.Writer, tmpl *template.Template, entries []listEntry) error {
+	for _, e := range entries {
+		if err := tmpl.Execute(w, e); err != nil {
+			return fmt.Errorf("failed to execute --format template: %w", err)
+		}
+		fmt.Fprintln(w)
+	}
 	return nil
 }
+
+// usesStatus reports whether tmpl refers to a field filled from git
+// status, i.e. Status or Dirty, anywhere including {{define}}d templates
+func usesStatus(tmpl *template.Template) bool {
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil && nodeUsesStatus(t.Tree.Root) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeUsesStatus(node parse.Node) bool {
+	isStatus := func(idents []string) bool {
+		for _, id := range idents {
+			if id == "Status" || id == "Dirty" {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch n := node.(type) {
+	case *parse.FieldNode:
+		return isStatus(n.Ident)
+	case *parse.VariableNode:
+		return isStatus(n.Ident)
+	case *parse.ChainNode:
+		return isStatus(n.Field) || nodeUsesStatus(n.Node)
+	case *parse.ListNode:
+		if n == nil {
+			return false
+		}
+		for _, child := range n.Nodes {
+			if nodeUsesStatus(child) {
+				return true
+			}
+		}
+	case *parse.ActionNode:
+		return nodeUsesStatus(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return false
+		}
+		for _, cmd := range n.Cmds {
+			if nodeUsesStatus(cmd) {
+				return true
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if nodeUsesStatus(arg) {
+				return true
+			}
+		}
+	case *parse.IfNode:
+		return nodeUsesStatus(&n.BranchNode)
+	case *parse.RangeNode:
+		return nodeUsesStatus(&n.BranchNode)
+	case *parse.WithNode:
+		return nodeUsesStatus(&n.BranchNode)
+	case *parse.BranchNode:
+		return nodeUsesStatus(n.Pipe) || nodeUsesStatus(n.List) || nodeUsesStatus(n.ElseList)
+	case *parse.TemplateNode:
+		return nodeUsesStatus(n.Pipe)
+	}
+	return false
+}
+
+// listEntries converts worktrees for output, loading their git status
+// concurrently if withStatus is set
+func listEntries(worktrees []*db.Worktree, withStatus bool) []listEntry {
+	entries := make([]listEntry, len(worktrees))
+	for i, wt := range worktrees {
+		entries[i] = listEntry{
+			ID:             wt.ID,
+			RepoID:         wt.RepoID,
+			Repo:           wt.RepoName,
+			RepoPath:       wt.RepoPath,
+			Branch:         wt.Branch,
+			Path:           wt.Path,
+			IsMain:         wt.IsMain,
+			CreatedAt:      wt.CreatedAt,
+			DeletedAt:      wt.DeletedAt,
+			AccessCount:    wt.AccessCount,
+			LastAccessedAt: wt.LastAccessedAt,
+			Frecency:       wt.Frecency,
+		}
+	}
+	if !withStatus {
+		return entries
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, syncWorkers)
+	for i := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if status, err := git.GetWorktreeStatus(entries[i].Path); err == nil {
+				entries[i].Status = status
+				entries[i].Dirty = status.IsDirty()
+			}
+		}()
+	}
+	wg.Wait()
+	return entries
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/gittest"
+)
+
+func testEntries() []listEntry {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []listEntry{
+		{
+			ID: 1, RepoID: 1, Repo: "api", RepoPath: "/src/api", Branch: "main", Path: "/src/api",
+			IsMain: true, CreatedAt: created, AccessCount: 3, LastAccessedAt: &created, Frecency: 1.5,
+			Dirty: true, Status: &git.WorktreeStatus{Modified: 2, Upstream: "origin/main", Ahead: 1},
+		},
+		{
+			ID: 2, RepoID: 1, Repo: "api", RepoPath: "/src/api", Branch: "feat\tx",
+			Path: "/src/api.worktrees/feat", CreatedAt: created,
+		},
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printJSON(&buf, testEntries()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+	if got[0]["repo"] != "api" || got[0]["is_main"] != true || got[0]["dirty"] != true {
+		t.Errorf("entry 0 = %v", got[0])
+	}
+	if status, _ := got[0]["status"].(map[string]any); status["modified"] != 2.0 || status["ahead"] != 1.0 {
+		t.Errorf("entry 0 status = %v", got[0]["status"])
+	}
+	if got[1]["status"] != nil || got[1]["last_accessed_at"] != nil {
+		t.Errorf("entry 1 = %v, want null status and last_accessed_at", got[1])
+	}
+}
+
+func TestPrintTSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printTSV(&buf, testEntries()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header and 2 rows:\n%s", len(lines), buf.String())
+	}
+	header := strings.Split(lines[0], "\t")
+	for i, line := range lines[1:] {
+		if fields := strings.Split(line, "\t"); len(fields) != len(header) {
+			t.Errorf("row %d has %d fields, header has %d: %q", i, len(fields), len(header), line)
+		}
+	}
+
+	column := func(row int, name string) string {
+		for i, h := range header {
+			if h == name {
+				return strings.Split(lines[row], "\t")[i]
+			}
+		}
+		t.Fatalf("no %s column", name)
+		return ""
+	}
+	if got := column(1, "modified"); got != "2" {
+		t.Errorf("modified = %q, want 2", got)
+	}
+	if got := column(1, "last_accessed_at"); got != "2026-01-02T03:04:05Z" {
+		t.Errorf("last_accessed_at = %q", got)
+	}
+	if got := column(2, "branch"); got != "feat x" {
+		t.Errorf("branch = %q, want the tab replaced", got)
+	}
+	if got := column(2, "upstream"); got != "" {
+		t.Errorf("upstream without status = %q, want empty", got)
+	}
+}
+
+func TestPrintTemplate(t *testing.T) {
+	tmpl := template.Must(template.New("format").Parse(
+		"{{.Repo}}/{{.Branch}}{{if .Dirty}} *{{end}}{{with .Status}} +{{.Ahead}}{{end}}"))
+
+	var buf bytes.Buffer
+	if err := printTemplate(&buf, tmpl, testEntries()); err != nil {
+		t.Fatal(err)
+	}
+	want := "api/main * +1\napi/feat\tx\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestUsesStatus(t *testing.T) {
+	tests := []struct {
+		format string
+		want   bool
+	}{
+		{"{{.Repo}} {{.Path}}", false},
+		{"{{if .Dirty}}dirty{{end}}", true},
+		{"{{.Status.Ahead}}", true},
+		{"{{with .Status}}{{.Ahead}}{{end}}", true},
+		{"{{if .IsMain}}{{else}}{{.Dirty}}{{end}}", true},
+		{"{{range $i, $e := .}}{{end}}{{$.Status}}", true},
+		{"{{printf \"%v\" .Dirty}}", true},
+		{"{{(.Status).Behind}}", true},
+		{`{{define "s"}}{{.Status}}{{end}}{{.Repo}}`, true},
+		{"Status .Dirty {{.Branch}}", false}, // text, not fields
+		{`{{.Repo}} {{"Status"}}`, false},
+	}
+	for _, tt := range tests {
+		tmpl := template.Must(template.New("format").Parse(tt.format))
+		if got := usesStatus(tmpl); got != tt.want {
+			t.Errorf("usesStatus(%q) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestListEntries_Status(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "repo")
+	gittest.Repo(t, repo)
+	if err := os.WriteFile(filepath.Join(repo, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	worktrees := []*db.Worktree{{RepoName: "repo", RepoPath: repo, Branch: "main", Path: repo}}
+
+	if e := listEntries(worktrees, false)[0]; e.Status != nil || e.Dirty {
+		t.Errorf("status loaded without withStatus: %+v", e)
+	}
+	e := listEntries(worktrees, true)[0]
+	if e.Status == nil || e.Status.Untracked != 1 || !e.Dirty {
+		t.Errorf("entry = %+v, status %+v, want dirty with 1 untracked file", e, e.Status)
+	}
+}
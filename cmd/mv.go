@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/shell"
+	"github.com/roveo/wt/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var mvKeepPath bool
+
+var mvCmd = &cobra.Command{
+	Use:     "mv <worktree> <new-branch | new-path>",
+	Aliases: []string{"move", "rename"},
+	Short:   "Rename a worktree's branch or move its directory",
+	Long: `Rename a worktree's branch or move the worktree to a new directory.
+
+The worktree is a path or repo/branch, like for 'wt remove'. A target
+starting with /, . or ~ is a new path and moves the worktree (git worktree
+move). Anything else is a new branch name and renames the branch (git branch
+-m); if the worktree's directory was named after the old branch by the
+worktrees_dir template, it is moved to match unless --keep-path is given.
+
+The worktree keeps its history in wt and its tmux window is renamed. If a
+step fails, the steps before it are undone.
+
+Examples:
+  wt mv api/fix/thing feature/thing
+  wt mv api/fix/thing ~/src/api-thing`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeSwitch,
+	RunE:              runMv,
+}
+
+func init() {
+	mvCmd.Flags().BoolVar(&mvKeepPath, "keep-path", false, "Rename the branch without moving the directory")
+	rootCmd.AddCommand(mvCmd)
+}
+
+func runMv(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	if git.IsInsideRepo(cwd) {
+		if err := ensureCurrentRepoInDB(database, cwd); err != nil {
+			return err
+		}
+	}
+	if err := syncAllRepos(database); err != nil {
+		return err
+	}
+
+	wt, err := findWorktree(database, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if wt == nil {
+		return fmt.Errorf("worktree not found: %s", args[0])
+	}
+
+	return moveWorktree(database, wt, args[1], mvKeepPath)
+}
+
+// moveWorktree renames a worktree's branch and/or moves it according to
+// target (see mvCmd), rolling back git changes if a later step fails
+func moveWorktree(database *sql.DB, wt *db.Worktree, target string, keepPath bool) error {
+	newBranch, newPath, err := planMove(database, wt, target, keepPath)
+	if err != nil {
+		return err
+	}
+	// Read before the move, as it may pull the directory out from under us
+	cwd, _ := os.Getwd()
+
+	// Undo steps run in reverse order if a later step fails
+	var undo []func() error
+	rollback := func(err error) error {
+		for i := len(undo) - 1; i >= 0; i-- {
+			if uerr := undo[i](); uerr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to roll back: %w", uerr))
+			}
+		}
+		return err
+	}
+
+	if newBranch != wt.Branch {
+		if err := git.RenameBranch(wt.RepoPath, wt.Branch, newBranch); err != nil {
+			return fmt.Errorf("failed to rename branch: %w", err)
+		}
+		undo = append(undo, func() error {
+			return git.RenameBranch(wt.RepoPath, newBranch, wt.Branch)
+		})
+	}
+
+	if newPath != wt.Path {
+		if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+			return rollback(fmt.Errorf("failed to create directory: %w", err))
+		}
+		if err := git.MoveWorktree(wt.RepoPath, wt.Path, newPath); err != nil {
+			if git.IsKind(err, git.ErrLocked) {
+				err = fmt.Errorf("%w\nUnlock it first with: git worktree unlock %s", err, wt.Path)
+			}
+			return rollback(fmt.Errorf("failed to move worktree: %w", err))
+		}
+		undo = append(undo, func() error {
+			return git.MoveWorktree(wt.RepoPath, newPath, wt.Path)
+		})
+	}
+
+	if err := db.UpdateWorktree(database, wt.ID, newBranch, newPath); err != nil {
+		return rollback(fmt.Errorf("failed to update database: %w", err))
+	}
+
+	if newBranch != wt.Branch {
+		renameTmuxWindow(wt, newBranch)
+		fmt.Fprintf(os.Stderr, "Renamed branch %s to %s\n", wt.Branch, newBranch)
+	}
+	if newPath != wt.Path {
+		fmt.Fprintf(os.Stderr, "Moved %s to %s\n", wt.Path, newPath)
+		followMove(cwd, wt.Path, newPath)
+	}
+	return nil
+}
+
+// planMove works out the new branch and path of a worktree for a mv target
+func planMove(database *sql.DB, wt *db.Worktree, target string, keepPath bool) (branch, path string, err error) {
+	branch, path = wt.Branch, wt.Path
+
+	if isPathTarget(target) {
+		// Only the own home directory; other users' (~alice) aren't expanded
+		if target == "~" || strings.HasPrefix(target, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", "", fmt.Errorf("failed to get home directory: %w", err)
+			}
+			target = filepath.Join(home, strings.TrimPrefix(target, "~"))
+		}
+		if path, err = filepath.Abs(target); err != nil {
+			return "", "", fmt.Errorf("invalid path: %w", err)
+		}
+	} else {
+		if wt.Branch == "(detached)" {
+			return "", "", fmt.Errorf("worktree has a detached HEAD, there is no branch to rename")
+		}
+		branch = target
+		if git.RefExists(wt.RepoPath, "refs/heads/"+branch) {
+			return "", "", fmt.Errorf("branch '%s' already exists", branch)
+		}
+
+		// Keep directories named after their branch in step
+		if !keepPath && !wt.IsMain {
+			repo, err := db.GetRepoByID(database, wt.RepoID)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to get repo: %w", err)
+			}
+			if repo != nil {
+				if derived, err := resolveWorktreePath(repo, wt.Branch); err == nil && derived == wt.Path {
+					if path, err = resolveWorktreePath(repo, branch); err != nil {
+						return "", "", err
+					}
+				}
+			}
+		}
+	}
+
+	if branch == wt.Branch && path == wt.Path {
+		return "", "", fmt.Errorf("worktree is already at %s on %s", path, branch)
+	}
+	if path != wt.Path {
+		if wt.IsMain {
+			return "", "", fmt.Errorf("cannot move the main worktree")
+		}
+		if _, err := os.Stat(path); err == nil {
+			return "", "", fmt.Errorf("%s already exists", path)
+		}
+	}
+	return branch, path, nil
+}
+
+// isPathTarget reports whether a mv target is a path rather than a branch
+func isPathTarget(target string) bool {
+	return filepath.IsAbs(target) || strings.HasPrefix(target, ".") || strings.HasPrefix(target, "~")
+}
+
+// renameTmuxWindow renames a worktree's tmux window after a branch rename
+func renameTmuxWindow(wt *db.Worktree, newBranch string) {
+	session, ok := worktreeWindowSession()
+	if !ok {
+		return
+	}
+	oldName := fmt.Sprintf("%s:%s", wt.RepoName, wt.Branch)
+	if !tmux.WindowExists(session, oldName) {
+		return
+	}
+	newName := fmt.Sprintf("%s:%s", wt.RepoName, newBranch)
+	if err := tmux.RenameWindow(session, oldName, newName); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to rename tmux window '%s': %v\n", oldName, err)
+	}
+}
+
+// followMove moves the shell along if its working directory cwd was inside
+// a worktree that was moved
+func followMove(cwd, oldPath, newPath string) {
+	rel, err := filepath.Rel(oldPath, cwd)
+	if cwd == "" || err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return
+	}
+	d := shell.NewDirectives()
+	d.Chdir(filepath.Join(newPath, rel))
+	flushDirectives(d)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var pathCmd = &cobra.Command{
+	Use:   "path <query>",
+	Short: "Print the path of the worktree matching a query",
+	Long: `Print the absolute path of the worktree best matching a query.
+
+Matching works like 'wt switch', but never opens the picker: when several
+worktrees match, the highest scoring one is printed. Exits with an error
+if nothing matches. Nothing is switched or recorded, so it is safe to use
+in scripts:
+
+  cd "$(wt path api/main)"`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeSwitch,
+	RunE:              runPath,
+}
+
+func init() {
+	rootCmd.AddCommand(pathCmd)
+}
+
+func runPath(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	var currentRepoPath string
+	if git.IsInsideRepo(cwd) {
+		if err := ensureCurrentRepoInDB(database, cwd); err != nil {
+			return err
+		}
+		currentRepoPath, _ = git.GetMainRepoPath(cwd)
+	}
+	if err := syncAllRepos(database); err != nil {
+		return err
+	}
+
+	worktrees, err := db.ListAllWorktreesWithRepoFirst(database, currentRepoPath)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	wt, err := pathMatch(worktrees, strings.Join(args, " "), currentRepoPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(wt.Path)
+	return nil
+}
+
+// pathMatch picks the worktree 'wt path' prints: the one query
+// unambiguously refers to, or else its highest scoring fuzzy match
+func pathMatch(worktrees []*db.Worktree, query, currentRepoPath string) (*db.Worktree, error) {
+	if wt, ok := ui.BestMatch(worktrees, query, currentRepoPath); ok {
+		return wt, nil
+	}
+	matches := ui.MatchWorktrees(worktrees, query)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no worktree matches %q", query)
+	}
+	return matches[0], nil
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/ui"
+)
+
+func TestPathMatch(t *testing.T) {
+	worktrees := []*db.Worktree{
+		{RepoName: "api", RepoPath: "/src/api", Branch: "main", Path: "/src/api", IsMain: true},
+		{RepoName: "api", RepoPath: "/src/api", Branch: "feature/auth", Path: "/src/api.worktrees/feature-auth"},
+		{RepoName: "web", RepoPath: "/src/web", Branch: "main", Path: "/src/web", IsMain: true},
+	}
+
+	tests := []struct {
+		query, currentRepo string
+		want               string // path, or "" for an error
+	}{
+		{"auth", "", "/src/api.worktrees/feature-auth"},
+		{"web/main", "", "/src/web"},
+		{"main", "/src/web", "/src/web"}, // current repo breaks the tie
+		{"zzz", "", ""},
+	}
+	for _, tt := range tests {
+		wt, err := pathMatch(worktrees, tt.query, tt.currentRepo)
+		got := ""
+		if err == nil {
+			got = wt.Path
+		}
+		if got != tt.want {
+			t.Errorf("pathMatch(%q, %q) = %q, %v, want %q", tt.query, tt.currentRepo, got, err, tt.want)
+		}
+	}
+
+	// Ambiguous queries print the best fuzzy match instead of failing
+	wt, err := pathMatch(worktrees, "main", "")
+	if err != nil {
+		t.Fatalf("ambiguous query: %v", err)
+	}
+	if best := ui.MatchWorktrees(worktrees, "main")[0]; wt != best {
+		t.Errorf("ambiguous query = %q, want the best match %q", wt.Path, best.Path)
+	}
+}
@@ -1,37 +1,79 @@
 package cmd
 
 import (
+	"database/sql"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/roveo/wt/internal/config"
 	"github.com/roveo/wt/internal/db"
 	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/proc"
+	"github.com/roveo/wt/internal/shell"
+	"github.com/roveo/wt/internal/tmux"
 	"github.com/roveo/wt/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	removeForce bool
+	removeForce              bool
+	removeDeleteBranch       string
+	removeDeleteRemoteBranch bool
 )
 
 var removeCmd = &cobra.Command{
-	Use:     "remove [worktree-path]",
+	Use:     "remove [worktree-path | repo/branch]",
 	Aliases: []string{"rm"},
 	Short:   "Remove a worktree",
 	Long: `Remove a worktree from the filesystem and database.
 
-If no path is specified, an interactive picker will be shown.
-The main worktree cannot be removed.`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runRemove,
+The worktree can be given as a path or as repo/branch (e.g. myapp/feature).
+If neither is specified, an interactive picker will be shown.
+The main worktree cannot be removed.
+
+--delete-branch also deletes the worktree's branch: "merged" (the default
+when the flag is given without a value) only if it is merged into the
+repo's default branch, including squash merges, "always" even if it isn't,
+"never" keeps it. --delete-remote-branch also deletes the branch's upstream
+on the remote. Both default to the [remove] section of the config.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktrees,
+	RunE:              runRemove,
 }
 
 func init() {
 	removeCmd.Flags().BoolVarP(&removeForce, "force", "f", false, "Force removal even with uncommitted changes")
+	removeCmd.Flags().StringVar(&removeDeleteBranch, "delete-branch", "", "Also delete the branch: merged, always or never")
+	removeCmd.Flags().Lookup("delete-branch").NoOptDefVal = "merged"
+	removeCmd.Flags().BoolVar(&removeDeleteRemoteBranch, "delete-remote-branch", false, "Also delete the branch's upstream on the remote")
 	rootCmd.AddCommand(removeCmd)
 }
 
 func runRemove(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	mode, deleteRemote := cfg.Remove.DeleteBranch, cfg.Remove.DeleteRemoteBranch
+	if cmd.Flags().Changed("delete-branch") {
+		mode = removeDeleteBranch
+		if !slices.Contains(config.DeleteBranchModes, mode) {
+			return fmt.Errorf("invalid --delete-branch %q: must be one of %s", mode, strings.Join(config.DeleteBranchModes, ", "))
+		}
+	}
+	if removeDeleteRemoteBranch {
+		if mode == "never" && cmd.Flags().Changed("delete-branch") {
+			return fmt.Errorf("--delete-remote-branch deletes the remote branch along with the local one, which --delete-branch=never keeps")
+		}
+		if mode == "never" {
+			mode = "merged"
+		}
+		deleteRemote = true
+	}
+
 	database, err := db.Default()
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
@@ -56,8 +98,8 @@ func runRemove(cmd *cobra.Command, args []string) error {
 	var worktree *db.Worktree
 
 	if len(args) > 0 {
-		// Path provided
-		worktree, err = db.GetWorktreeByPath(database, args[0])
+		// Path or repo/branch provided
+		worktree, err = findWorktree(database, args[0])
 		if err != nil {
 			return fmt.Errorf("failed to get worktree: %w", err)
 		}
@@ -98,8 +140,24 @@ func runRemove(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("cannot remove the main worktree")
 	}
 
+	if err := checkNotInUse(worktree); err != nil {
+		return err
+	}
+	check, err := checkRemoval(worktree)
+	if err != nil {
+		return err
+	}
+	plan := planBranch(worktree)
+	plan.chooseMode(mode, deleteRemote)
+	printRemovalCheck(check, !plan.delete, "")
+	printBranchPlan(plan, "")
+	if check.Destructive() && !removeForce {
+		return fmt.Errorf("the worktree has uncommitted changes; use --force to discard them")
+	}
+
 	// Confirm removal
-	confirmed, err := ui.Confirm(fmt.Sprintf("Remove worktree '%s/%s' at %s?", worktree.RepoName, worktree.Branch, worktree.Path))
+	confirmed, err := confirmRemoval(fmt.Sprintf("Remove worktree '%s/%s' at %s?", worktree.RepoName, worktree.Branch, worktree.Path),
+		check.Destructive() || plan.destructive(check), worktree.Branch)
 	if err != nil {
 		return err
 	}
@@ -110,25 +168,477 @@ func runRemove(cmd *cobra.Command, args []string) error {
 
 	// Remove worktree from git
 	fmt.Fprintf(os.Stderr, "Removing worktree...\n")
-	var removeErr error
+	policy := snapshotRequired
 	if removeForce {
-		removeErr = git.RemoveWorktreeForce(worktree.RepoPath, worktree.Path)
-	} else {
-		removeErr = git.RemoveWorktree(worktree.RepoPath, worktree.Path)
+		policy = snapshotOptional
 	}
+	if err := removeTrackedWorktree(database, worktree, forceRemoval(check), policy); err != nil {
+		if git.IsKind(err, git.ErrDirtyWorktree) {
+			return fmt.Errorf("%w\nThe worktree has uncommitted changes; use --force to discard them", err)
+		}
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Worktree removed successfully.\n")
+	deleteWorktreeBranch(worktree.RepoPath, plan)
+	return nil
+}
+
+// snapshotPolicy tells what to do with a worktree that can't be
+// snapshotted before its removal
+type snapshotPolicy int
+
+const (
+	snapshotRequired snapshotPolicy = iota // keep it
+	snapshotAsk                            // ask whether to remove it anyway
+	snapshotOptional                       // remove it anyway, on an explicit --force
+)
+
+// forceRemoval tells whether git has to force the removal of a worktree:
+// when --force was given, or its uncommitted changes are to be discarded
+func forceRemoval(check *git.RemovalCheck) bool {
+	return removeForce || check.Destructive()
+}
 
-	if removeErr != nil {
-		return fmt.Errorf("failed to remove worktree: %w (use --force to force removal)", removeErr)
+// removeTrackedWorktree snapshots a worktree so it can be restored, removes
+// it from git, kills its tmux window and soft-deletes it from the database.
+// Refuses if a process is working inside the worktree. With force, git
+// removes it even if it is dirty or has submodules. policy decides what
+// happens when the snapshot fails.
+func removeTrackedWorktree(database *sql.DB, wt *db.Worktree, force bool, policy snapshotPolicy) error {
+	if err := checkNotInUse(wt); err != nil {
+		return err
+	}
+
+	cwd, _ := os.Getwd()
+	snap, err := snapshotWorktree(database, wt)
+	if err != nil {
+		switch policy {
+		case snapshotOptional:
+			fmt.Fprintf(os.Stderr, "Warning: failed to snapshot worktree, it can't be restored: %v\n", err)
+		case snapshotAsk:
+			fmt.Fprintf(os.Stderr, "Failed to snapshot worktree: %v\n", err)
+			confirmed, cerr := ui.Confirm(fmt.Sprintf("Remove %s/%s without a snapshot? It can't be restored.", wt.RepoName, wt.Branch))
+			if cerr != nil {
+				return cerr
+			}
+			if !confirmed {
+				return fmt.Errorf("failed to snapshot worktree, nothing was removed: %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to snapshot worktree, nothing was removed: %w\nUse --force to remove it without a snapshot", err)
+		}
+	}
+
+	if force {
+		err = git.RemoveWorktreeForce(wt.RepoPath, wt.Path)
+	} else {
+		err = git.RemoveWorktree(wt.RepoPath, wt.Path)
+	}
+	if err != nil {
+		if snap != nil {
+			if derr := discardSnapshot(database, snap); derr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to discard snapshot %d: %v\n", snap.ID, derr)
+			}
+		}
+		if git.IsKind(err, git.ErrLocked) {
+			return fmt.Errorf("failed to remove worktree: %w\nUnlock it first with: git worktree unlock %s", err, wt.Path)
+		}
+		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
 	// Clean up tmux window if it exists
-	cleanupTmuxWindow(worktree)
+	cleanupTmuxWindow(wt)
+	leaveWorktree(cwd, wt)
 
 	// Soft-delete from database
-	if err := db.SoftDeleteWorktree(database, worktree.ID); err != nil {
+	if err := db.SoftDeleteWorktree(database, wt.ID); err != nil {
 		return fmt.Errorf("failed to update database: %w", err)
 	}
 
-	fmt.Fprintf(os.Stderr, "Worktree removed successfully.\n")
+	if snap != nil {
+		fmt.Fprintf(os.Stderr, "Saved snapshot %d, restore it with 'wt undo' or 'wt restore %d'\n", snap.ID, snap.ID)
+	}
 	return nil
 }
+
+// leaveWorktree moves the calling shell to the main worktree if its working
+// directory cwd was inside a removed worktree
+func leaveWorktree(cwd string, wt *db.Worktree) {
+	rel, err := filepath.Rel(wt.Path, cwd)
+	if cwd == "" || err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return
+	}
+	d := shell.NewDirectives()
+	d.Chdir(wt.RepoPath)
+	flushDirectives(d)
+}
+
+// findWorktree looks up a tracked worktree by path (absolute or relative
+// to the current directory) or by repo/branch name
+func findWorktree(database *sql.DB, arg string) (*db.Worktree, error) {
+	if path, err := filepath.Abs(arg); err == nil {
+		wt, err := db.GetWorktreeByPath(database, path)
+		if err != nil || wt != nil {
+			return wt, err
+		}
+	}
+
+	repoName, branch, ok := strings.Cut(arg, "/")
+	if !ok {
+		return nil, nil
+	}
+	worktrees, err := db.ListAllWorktrees(database)
+	if err != nil {
+		return nil, err
+	}
+	for _, wt := range worktrees {
+		if wt.RepoName == repoName && wt.Branch == branch {
+			return wt, nil
+		}
+	}
+	return nil, nil
+}
+
+// maxListed is how many files, commits or stashes a removal dialog lists
+const maxListed = 10
+
+// checkRemoval collects what removing a worktree puts at stake. A worktree
+// whose directory is gone has nothing left to lose.
+func checkRemoval(wt *db.Worktree) (*git.RemovalCheck, error) {
+	if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+		return &git.RemovalCheck{}, nil
+	}
+	check, err := git.CheckRemoval(wt.Path, wt.Branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s/%s: %w", wt.RepoName, wt.Branch, err)
+	}
+	return check, nil
+}
+
+// printRemovalCheck lists the uncommitted files, unpushed commits and
+// stashes of a worktree about to be removed. branchKept tells whether the
+// unpushed commits are known to stay on the branch.
+func printRemovalCheck(check *git.RemovalCheck, branchKept bool, indent string) {
+	unpushed := "Commits not pushed to any remote:"
+	if branchKept {
+		unpushed = "Commits not pushed to any remote (kept on the branch):"
+	}
+	sections := []struct {
+		title string
+		lines []string
+	}{
+		{"Uncommitted changes (will be discarded):", check.Uncommitted},
+		{unpushed, check.Unpushed},
+		{"Stashes made on the branch (kept):", check.Stashes},
+	}
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s%s\n", indent, section.title)
+		for i, line := range section.lines {
+			if i == maxListed {
+				fmt.Fprintf(os.Stderr, "%s    ... and %d more\n", indent, len(section.lines)-maxListed)
+				break
+			}
+			fmt.Fprintf(os.Stderr, "%s    %s\n", indent, line)
+		}
+	}
+}
+
+// confirmRemoval asks to confirm a removal. Destructive ones take typing
+// want rather than a single keypress.
+func confirmRemoval(message string, destructive bool, want string) (bool, error) {
+	if !destructive {
+		return ui.Confirm(message)
+	}
+	return ui.ConfirmTyped(message, want)
+}
+
+// checkNotInUse refuses to remove a worktree that processes are working
+// in, as they would be left in a deleted directory
+func checkNotInUse(wt *db.Worktree) error {
+	procs := processesInWorktree(wt)
+	if len(procs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s/%s is in use by %s\nClose them or cd out of %s first", wt.RepoName, wt.Branch, proc.Describe(procs), wt.Path)
+}
+
+// processesInWorktree returns the processes working inside a worktree. The
+// shell wt was called from through the shell wrapper doesn't count, as it
+// is moved out after the removal, and neither do the shells of the worktree's own tmux window,
+// since the window is closed along with the worktree; programs running in
+// them do.
+func processesInWorktree(wt *db.Worktree) []proc.Process {
+	procs, err := proc.InDir(wt.Path)
+	if err != nil {
+		// Can't tell, e.g. /proc isn't mounted
+		return nil
+	}
+	if os.Getenv(shell.DirectiveFileEnv) != "" {
+		// Only the shell wrapper can cd its shell out
+		parent := os.Getppid()
+		procs = slices.DeleteFunc(procs, func(p proc.Process) bool {
+			return p.PID == parent
+		})
+	}
+
+	if session, ok := worktreeWindowSession(); ok {
+		windowName := fmt.Sprintf("%s:%s", wt.RepoName, wt.Branch)
+		if tmux.WindowExists(session, windowName) {
+			if pids, err := tmux.WindowPanePIDs(session, windowName); err == nil {
+				procs = slices.DeleteFunc(procs, func(p proc.Process) bool {
+					return slices.Contains(pids, p.PID)
+				})
+			}
+		}
+	}
+	return procs
+}
+
+// branchPlan is what happens to the branch of a worktree being removed
+type branchPlan struct {
+	branch       string
+	base         string // default branch it is checked against, empty if unknown
+	merged       bool   // its changes are in base
+	keep         string // why it can't be deleted, empty if it can
+	remote       string // remote of its upstream branch, empty if it has none
+	remoteBranch string
+	remoteMerged bool   // the upstream branch's changes are in base
+	remoteKeep   string // why the upstream can't be deleted, empty if it can
+
+	requested       bool // deletion was asked for, even if the branch is kept
+	remoteRequested bool
+	delete          bool
+	deleteRemote    bool
+}
+
+// planBranch checks whether the branch of a worktree can be deleted along
+// with it and whether it is merged. Nothing is deleted until chosen.
+func planBranch(wt *db.Worktree) *branchPlan {
+	p := &branchPlan{branch: wt.Branch}
+	if wt.Branch == "(detached)" {
+		p.keep = "detached HEAD"
+		return p
+	}
+
+	base, err := git.DefaultBranchRef(wt.RepoPath)
+	baseBranch := strings.TrimPrefix(base, "origin/")
+	if err == nil {
+		if wt.Branch == baseBranch {
+			p.keep = "it is the default branch"
+			return p
+		}
+		p.base = base
+		p.merged = git.HasOwnCommits(wt.RepoPath, wt.Branch) && isBranchMerged(wt.RepoPath, wt.Branch, base)
+	}
+
+	if remote, name, ok := git.UpstreamOf(wt.RepoPath, wt.Branch); ok {
+		p.remote, p.remoteBranch = remote, name
+		p.remoteKeep = remoteKeepReason(wt.RepoPath, wt.Branch, remote, name, baseBranch)
+		p.remoteMerged = p.base != "" && isBranchMerged(wt.RepoPath, remote+"/"+name, base)
+	}
+	return p
+}
+
+// remoteKeepReason tells why the upstream branch remote/name of branch must
+// not be deleted along with it: it is someone else's branch unless it has
+// the same name and no other local branch tracks it. Empty if it can go.
+func remoteKeepReason(repoPath, branch, remote, name, baseBranch string) string {
+	if name == baseBranch {
+		return "it is the default branch"
+	}
+	if name != branch {
+		return "its name differs from " + branch
+	}
+	tracking, err := git.BranchesTracking(repoPath, remote, name)
+	if err != nil {
+		return "can't tell which branches track it"
+	}
+	others := slices.DeleteFunc(tracking, func(b string) bool { return b == branch })
+	if len(others) > 0 {
+		return "also tracked by " + strings.Join(others, ", ")
+	}
+	return ""
+}
+
+// isBranchMerged reports whether ref is merged or squash-merged into base
+func isBranchMerged(repoPath, ref, base string) bool {
+	if merged, err := git.IsMerged(repoPath, ref, base); err == nil && merged {
+		return true
+	}
+	squashed, err := git.IsSquashMerged(repoPath, ref, base)
+	return err == nil && squashed
+}
+
+// choose deletes the branch if it is merged and merged is set, or if it
+// isn't and unmerged is set. With remote, its upstream branch goes too, if
+// it is merged or unmerged is set.
+func (p *branchPlan) choose(merged, unmerged, remote bool) {
+	p.requested = merged || unmerged
+	if p.merged {
+		p.delete = p.keep == "" && merged
+	} else {
+		p.delete = p.keep == "" && unmerged
+	}
+	p.remoteRequested = remote
+	p.deleteRemote = p.delete && remote && p.remote != "" && p.remoteKeep == "" && (p.remoteMerged || unmerged)
+}
+
+// chooseMode applies a [remove] delete_branch mode
+func (p *branchPlan) chooseMode(mode string, remote bool) {
+	p.choose(mode != "never", mode == "always", remote)
+}
+
+// destructive reports whether deleting the branch loses commits that exist
+// nowhere else: unmerged commits that were never pushed, or the ones on an
+// unmerged remote branch
+func (p *branchPlan) destructive(check *git.RemovalCheck) bool {
+	return p.delete && !p.merged && len(check.Unpushed) > 0 || p.deleteRemote && !p.remoteMerged
+}
+
+// mergeState describes how the branch relates to the default branch
+func (p *branchPlan) mergeState() string {
+	switch {
+	case p.base == "":
+		return "default branch unknown"
+	case p.merged:
+		return "merged into " + p.base
+	default:
+		return "not merged into " + p.base
+	}
+}
+
+// printBranchPlan tells what happens to the branch, if its deletion was
+// asked for
+func printBranchPlan(p *branchPlan, indent string) {
+	switch {
+	case p.deleteRemote:
+		fmt.Fprintf(os.Stderr, "%sBranch %s and %s/%s will be deleted (%s)\n", indent, p.branch, p.remote, p.remoteBranch, p.mergeState())
+	case p.delete:
+		fmt.Fprintf(os.Stderr, "%sBranch %s will be deleted (%s)\n", indent, p.branch, p.mergeState())
+		if p.remoteRequested && p.remoteKeep != "" {
+			fmt.Fprintf(os.Stderr, "%s%s/%s is kept (%s)\n", indent, p.remote, p.remoteBranch, p.remoteKeep)
+		}
+	case p.requested && p.keep == "":
+		fmt.Fprintf(os.Stderr, "%sBranch %s is kept (%s)\n", indent, p.branch, p.mergeState())
+	case p.requested && p.branch != "(detached)":
+		fmt.Fprintf(os.Stderr, "%sBranch %s is kept (%s)\n", indent, p.branch, p.keep)
+	}
+}
+
+// pickBranchDeletion shows the branch options of the picker's delete
+// dialog, preselected from the [remove] config, and applies the choice to
+// the plans. The dialog confirms the removal: returns false if the user
+// cancelled.
+func pickBranchDeletion(title string, plans []*branchPlan, cfg config.RemoveConfig) (bool, error) {
+	mergedDefault := cfg.DeleteBranch != "never"
+	unmergedDefault := cfg.DeleteBranch == "always"
+
+	var items []ui.SelectItem
+	if len(plans) == 1 {
+		p := plans[0]
+		p.chooseMode(cfg.DeleteBranch, cfg.DeleteRemoteBranch)
+		items = []ui.SelectItem{
+			{Label: "Delete branch " + p.branch, Detail: p.mergeState(), Selected: p.delete},
+			{Label: "Delete the remote branch", Detail: "no upstream", Disabled: true},
+		}
+		if p.remote != "" && p.remoteKeep != "" {
+			items[1] = ui.SelectItem{
+				Label:    fmt.Sprintf("Delete %s/%s on the remote", p.remote, p.remoteBranch),
+				Detail:   p.remoteKeep,
+				Disabled: true,
+			}
+		} else if p.remote != "" {
+			items[1] = ui.SelectItem{
+				Label:    fmt.Sprintf("Delete %s/%s on the remote", p.remote, p.remoteBranch),
+				Detail:   "along with the local branch",
+				Selected: p.deleteRemote,
+			}
+		}
+	} else {
+		var deletable, merged, upstreams int
+		for _, p := range plans {
+			if p.keep != "" {
+				continue
+			}
+			deletable++
+			if p.merged {
+				merged++
+			}
+			if p.remote != "" && p.remoteKeep == "" {
+				upstreams++
+			}
+		}
+		items = []ui.SelectItem{
+			{
+				Label:    "Delete merged branches",
+				Detail:   fmt.Sprintf("%d merged into the default branch", merged),
+				Selected: mergedDefault && merged > 0,
+				Disabled: merged == 0,
+			},
+			{
+				Label:    "Delete unmerged branches",
+				Detail:   fmt.Sprintf("%d not merged", deletable-merged),
+				Selected: unmergedDefault && deletable > merged,
+				Disabled: deletable == merged,
+			},
+			{
+				Label:    "Delete remote branches",
+				Detail:   fmt.Sprintf("%d with an upstream, along with the local branch", upstreams),
+				Selected: cfg.DeleteRemoteBranch && upstreams > 0,
+				Disabled: upstreams == 0,
+			},
+		}
+	}
+
+	selected, err := ui.MultiSelect(title, items)
+	if err != nil || selected == nil {
+		return false, err
+	}
+	chosen := make([]bool, len(items))
+	for _, i := range selected {
+		chosen[i] = true
+	}
+
+	if len(plans) == 1 {
+		// The branch was picked explicitly, whether it is merged or not
+		p := plans[0]
+		p.requested = chosen[0]
+		p.remoteRequested = chosen[1]
+		p.delete = chosen[0]
+		p.deleteRemote = chosen[0] && chosen[1] && p.remoteKeep == ""
+		return true, nil
+	}
+	for _, p := range plans {
+		p.choose(chosen[0], chosen[1], chosen[2])
+	}
+	return true, nil
+}
+
+// deleteWorktreeBranch deletes the branch of a removed worktree, and its
+// upstream branch, as planned. Failures are only warned about, since the
+// worktree is already gone.
+func deleteWorktreeBranch(repoPath string, p *branchPlan) {
+	if !p.delete {
+		return
+	}
+	// Force, as git branch -d only knows about the upstream and HEAD, not
+	// the default branch or squash merges
+	if err := git.DeleteBranch(repoPath, p.branch, true); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to delete branch %s: %v\n", p.branch, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Deleted branch %s\n", p.branch)
+
+	if !p.deleteRemote {
+		return
+	}
+	if err := git.DeleteRemoteBranch(repoPath, p.remote, p.remoteBranch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to delete %s/%s: %v\n", p.remote, p.remoteBranch, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Deleted %s/%s\n", p.remote, p.remoteBranch)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/gittest"
+)
+
+// branchRepo creates a clone of a remote whose main has a merged, a
+// squash-merged and an unmerged branch, a branch without commits, a branch
+// tracking an upstream of another name and one sharing its upstream
+func branchRepo(t *testing.T) string {
+	_, repo := gittest.Clone(t)
+	// A clone of an empty remote has no origin/HEAD to find main by
+	gittest.Run(t, repo, "remote", "set-head", "origin", "main")
+
+	commitOn := func(branch string) {
+		t.Helper()
+		gittest.Run(t, repo, "switch", "-q", "-c", branch, "main")
+		if err := os.WriteFile(filepath.Join(repo, branch+".txt"), []byte(branch), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gittest.Run(t, repo, "add", branch+".txt")
+		gittest.Run(t, repo, "commit", "-q", "-m", branch)
+		gittest.Run(t, repo, "push", "-q", "-u", "origin", branch)
+	}
+
+	commitOn("merged")
+	commitOn("develop")
+	commitOn("squashed")
+	commitOn("unmerged")
+	gittest.Run(t, repo, "switch", "-q", "main")
+	gittest.Run(t, repo, "merge", "-q", "--no-ff", "-m", "merge", "merged")
+	gittest.Run(t, repo, "merge", "-q", "--no-ff", "-m", "merge develop", "develop")
+	gittest.Run(t, repo, "merge", "-q", "--squash", "squashed")
+	gittest.Run(t, repo, "commit", "-q", "-m", "squash")
+	gittest.Run(t, repo, "push", "-q", "origin", "main")
+
+	gittest.Run(t, repo, "branch", "fresh", "main")
+	gittest.Run(t, repo, "switch", "-q", "-c", "feature", "develop")
+	gittest.Run(t, repo, "branch", "--set-upstream-to=origin/develop", "feature")
+	gittest.Run(t, repo, "branch", "--track", "twin", "origin/unmerged")
+	gittest.Run(t, repo, "switch", "-q", "main")
+	return repo
+}
+
+func TestPlanBranch(t *testing.T) {
+	repo := branchRepo(t)
+
+	tests := []struct {
+		branch     string
+		merged     bool
+		keep       bool
+		remote     string // upstream branch name
+		remoteKeep bool
+	}{
+		{branch: "merged", merged: true, remote: "merged"},
+		{branch: "squashed", merged: true, remote: "squashed"},
+		{branch: "unmerged", remote: "unmerged", remoteKeep: true}, // twin tracks it too
+		{branch: "fresh"}, // no commits of its own
+		{branch: "feature", remote: "develop", remoteKeep: true}, // develop is merged, feature has no commits
+		{branch: "main", keep: true},
+		{branch: "(detached)", keep: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			p := planBranch(&db.Worktree{RepoPath: repo, Branch: tt.branch})
+			if p.merged != tt.merged {
+				t.Errorf("merged = %v, want %v (%s)", p.merged, tt.merged, p.mergeState())
+			}
+			if (p.keep != "") != tt.keep {
+				t.Errorf("keep = %q, want kept: %v", p.keep, tt.keep)
+			}
+			if p.keep == "" && p.base != "origin/main" {
+				t.Errorf("base = %q, want origin/main", p.base)
+			}
+			if p.remoteBranch != tt.remote {
+				t.Errorf("upstream = %q, want %q", p.remoteBranch, tt.remote)
+			}
+			if (p.remoteKeep != "") != tt.remoteKeep {
+				t.Errorf("remoteKeep = %q, want kept: %v", p.remoteKeep, tt.remoteKeep)
+			}
+			if p.chooseMode("always", true); tt.remoteKeep && p.deleteRemote {
+				t.Errorf("%s/%s must not be deleted: %s", p.remote, p.remoteBranch, p.remoteKeep)
+			}
+		})
+	}
+}
+
+func TestPlanBranchNoDefaultBranch(t *testing.T) {
+	repo := t.TempDir()
+	gittest.Run(t, repo, "init", "-q", "-b", "trunk")
+	gittest.Commit(t, repo, "init")
+	gittest.Run(t, repo, "switch", "-q", "-c", "work")
+	gittest.Commit(t, repo, "work")
+
+	p := planBranch(&db.Worktree{RepoPath: repo, Branch: "work"})
+	if p.keep != "" || p.base != "" || p.merged {
+		t.Fatalf("got %+v, want a deletable, unmerged branch without a base", p)
+	}
+	p.chooseMode("merged", false)
+	if p.delete {
+		t.Error("merged mode must not delete a branch that can't be checked")
+	}
+	p.chooseMode("always", false)
+	if !p.delete {
+		t.Error("always mode should delete it")
+	}
+}
+
+func TestChooseBranchDeletion(t *testing.T) {
+	merged := branchPlan{branch: "a", base: "origin/main", merged: true, remote: "origin", remoteBranch: "a", remoteMerged: true}
+	unmerged := branchPlan{branch: "b", base: "origin/main", remote: "origin", remoteBranch: "b"}
+	unmergedRemoteKept := unmerged
+	unmergedRemoteKept.remoteKeep = "also tracked by c"
+	kept := branchPlan{branch: "main", keep: "it is the default branch"}
+
+	unpushed := &git.RemovalCheck{Unpushed: []string{"abc123 work"}}
+	pushed := &git.RemovalCheck{}
+
+	tests := []struct {
+		name         string
+		plan         branchPlan
+		mode         string
+		remote       bool
+		check        *git.RemovalCheck
+		delete       bool
+		deleteRemote bool
+		destructive  bool
+	}{
+		{"merged never", merged, "never", true, pushed, false, false, false},
+		{"merged merged", merged, "merged", false, pushed, true, false, false},
+		{"merged merged remote", merged, "merged", true, pushed, true, true, false},
+		{"merged always remote", merged, "always", true, pushed, true, true, false},
+		{"unmerged never", unmerged, "never", false, unpushed, false, false, false},
+		{"unmerged merged", unmerged, "merged", true, unpushed, false, false, false},
+		{"unmerged always pushed", unmerged, "always", false, pushed, true, false, false},
+		{"unmerged always unpushed", unmerged, "always", false, unpushed, true, false, true},
+		{"unmerged always remote", unmerged, "always", true, pushed, true, true, true},
+		{"unmerged always remote kept", unmergedRemoteKept, "always", true, pushed, true, false, false},
+		{"default branch always", kept, "always", true, unpushed, false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.plan
+			p.chooseMode(tt.mode, tt.remote)
+			if p.delete != tt.delete || p.deleteRemote != tt.deleteRemote {
+				t.Errorf("delete, deleteRemote = %v, %v, want %v, %v", p.delete, p.deleteRemote, tt.delete, tt.deleteRemote)
+			}
+			if got := p.destructive(tt.check); got != tt.destructive {
+				t.Errorf("destructive = %v, want %v", got, tt.destructive)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var repoCmd = &cobra.Command{
+	Use:   "repo",
+	Short: "Manage the tracked repositories",
+	Long: `Manage the set of repositories wt tracks.
+
+Repos are also tracked automatically when wt runs inside them.`,
+}
+
+var repoAddCmd = &cobra.Command{
+	Use:   "add <path>...",
+	Short: "Start tracking a repository",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runRepoAdd,
+}
+
+var repoRmCmd = &cobra.Command{
+	Use:     "rm <name | path>...",
+	Aliases: []string{"remove"},
+	Short:   "Stop tracking a repository",
+	Long: `Stop tracking a repository. Its worktrees are left on disk.
+
+Running wt inside the repository tracks it again.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeRepoNames,
+	RunE:              runRepoRm,
+}
+
+var repoListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List tracked repositories",
+	Args:    cobra.NoArgs,
+	RunE:    runRepoList,
+}
+
+var repoRelocateCmd = &cobra.Command{
+	Use:   "relocate <old> <new>",
+	Short: "Update a repository that was moved on disk",
+	Long: `Update a repository that was moved on disk.
+
+<old> is the repo's name or its old path, <new> is where it lives now.
+Worktree paths under the old location are updated to match, so worktrees
+moved along with the repo (e.g. a sibling .worktrees directory moved at the
+same time) keep their history. Links between the repo and its worktrees are
+fixed with git worktree repair.
+
+Example:
+  mv ~/src/api ~/work/api && mv ~/src/api.worktrees ~/work/api.worktrees
+  wt repo relocate ~/src/api ~/work/api`,
+	Args: cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return completeRepoNames(cmd, args, toComplete)
+	},
+	RunE: runRepoRelocate,
+}
+
+func init() {
+	repoCmd.AddCommand(repoAddCmd, repoRmCmd, repoListCmd, repoRelocateCmd)
+	rootCmd.AddCommand(repoCmd)
+}
+
+func runRepoAdd(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	for _, arg := range args {
+		path, err := filepath.Abs(arg)
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		if !git.IsInsideRepo(path) {
+			return fmt.Errorf("%s is not a git repository", arg)
+		}
+
+		repo, added, err := trackRepo(database, path)
+		if err != nil {
+			return err
+		}
+		if !added {
+			fmt.Fprintf(os.Stderr, "Already tracking %s (%s)\n", repo.Name, repo.Path)
+			continue
+		}
+		if err := syncWorktrees(database, repo); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to sync worktrees of %s: %v\n", repo.Name, err)
+		}
+		fmt.Fprintf(os.Stderr, "Tracking %s (%s)\n", repo.Name, repo.Path)
+	}
+	return nil
+}
+
+func runRepoRm(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	for _, arg := range args {
+		repo, err := findRepo(database, arg)
+		if err != nil {
+			return err
+		}
+		if err := db.SoftDeleteRepo(database, repo.ID); err != nil {
+			return fmt.Errorf("failed to remove repo: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Stopped tracking %s (%s), its worktrees were left on disk\n", repo.Name, repo.Path)
+	}
+	return nil
+}
+
+func runRepoList(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	repos, err := db.ListRepos(database)
+	if err != nil {
+		return fmt.Errorf("failed to list repos: %w", err)
+	}
+	counts, err := db.CountWorktrees(database)
+	if err != nil {
+		return fmt.Errorf("failed to count worktrees: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPATH\tWORKTREES\tLAST SYNCED")
+	for _, repo := range repos {
+		path := repo.Path
+		if _, err := os.Stat(repo.Path); os.IsNotExist(err) {
+			path += " (missing)"
+		}
+		synced := "never"
+		if repo.LastSyncedAt != nil {
+			synced = ui.FormatAge(*repo.LastSyncedAt)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", repo.Name, path, counts[repo.ID], synced)
+	}
+	return w.Flush()
+}
+
+func runRepoRelocate(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	repo, err := findRepo(database, args[0])
+	if err != nil {
+		return err
+	}
+
+	newPath, err := filepath.Abs(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+	if !git.IsInsideRepo(newPath) {
+		return fmt.Errorf("%s is not a git repository", args[1])
+	}
+	if mainPath, err := git.GetMainRepoPath(newPath); err != nil || mainPath != newPath {
+		return fmt.Errorf("%s is not the main worktree of a repository", args[1])
+	}
+	if newPath == repo.Path {
+		return fmt.Errorf("%s is already at %s", repo.Name, newPath)
+	}
+
+	worktrees, err := db.ListWorktreesByRepo(database, repo.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list worktrees: %w", err)
+	}
+
+	// Work out where each worktree ended up; ones that didn't move along
+	// with the repo keep their path
+	moved := make(map[int64]string)
+	var movedPaths []string
+	for _, wt := range worktrees {
+		if p, ok := relocatePath(wt.Path, repo.Path, newPath, repo.WorktreesDir); ok && !wt.IsMain {
+			if _, err := os.Stat(p); err == nil {
+				moved[wt.ID] = p
+				movedPaths = append(movedPaths, p)
+			}
+		}
+	}
+
+	// Point the repo at its worktrees and the worktrees back at the repo
+	if err := git.RepairWorktrees(newPath, movedPaths...); err != nil {
+		return fmt.Errorf("failed to repair worktrees: %w", err)
+	}
+
+	worktreesDir := repo.WorktreesDir
+	if p, ok := relocatePath(worktreesDir, repo.Path, newPath, repo.WorktreesDir); ok {
+		if _, err := os.Stat(p); err == nil {
+			worktreesDir = p
+		}
+	}
+
+	stopBackgroundScan()
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := db.RelocateRepo(tx, repo.ID, newPath, git.GetRepoName(newPath), worktreesDir); err != nil {
+		return fmt.Errorf("failed to update repo: %w", err)
+	}
+	for _, wt := range worktrees {
+		path := moved[wt.ID]
+		if wt.IsMain {
+			path = newPath
+		}
+		if path == "" {
+			continue
+		}
+		if err := db.UpdateWorktree(tx, wt.ID, wt.Branch, path); err != nil {
+			return fmt.Errorf("failed to update worktree: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save relocation: %w", err)
+	}
+
+	repo.Path, repo.Name, repo.WorktreesDir = newPath, git.GetRepoName(newPath), worktreesDir
+	if err := syncWorktrees(database, repo); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to sync worktrees of %s: %v\n", repo.Name, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Relocated %s to %s (%d worktree(s) moved along)\n", repo.Name, newPath, len(movedPaths))
+	return nil
+}
+
+// findRepo finds a tracked repo by path or name. The path doesn't need to
+// exist, so repos that were moved or deleted can still be found.
+func findRepo(database *sql.DB, arg string) (*db.Repo, error) {
+	if path, err := filepath.Abs(arg); err == nil {
+		if repo, err := db.GetRepoByPath(database, path); err != nil || repo != nil {
+			return repo, err
+		}
+		// A path inside the repo or one of its worktrees
+		if mainPath, err := git.GetMainRepoPath(path); err == nil {
+			if repo, err := db.GetRepoByPath(database, mainPath); err != nil || repo != nil {
+				return repo, err
+			}
+		}
+	}
+
+	repos, err := db.ListRepos(database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+	var matches []*db.Repo
+	for _, repo := range repos {
+		if repo.Name == arg {
+			matches = append(matches, repo)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("repo not found: %s", arg)
+	case 1:
+		return matches[0], nil
+	}
+	var paths []string
+	for _, repo := range matches {
+		paths = append(paths, "  "+repo.Path)
+	}
+	return nil, fmt.Errorf("several repos are named %s, use a path instead:\n%s", arg, strings.Join(paths, "\n"))
+}
+
+// relocatePath rewrites a path under oldRoot to the same place under
+// newRoot. Paths in the repo's worktreesDir move too if it is a sibling
+// named after the repo (like the default <repo>.worktrees); other siblings
+// sharing the repo's name, like <repo>.bak, don't.
+func relocatePath(path, oldRoot, newRoot, worktreesDir string) (string, bool) {
+	for _, dir := range []string{oldRoot, worktreesDir} {
+		if !strings.HasPrefix(dir, oldRoot) {
+			continue
+		}
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return newRoot + strings.TrimPrefix(path, oldRoot), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import "testing"
+
+func TestRelocatePath(t *testing.T) {
+	tests := []struct {
+		path         string
+		worktreesDir string
+		want         string // empty if the path doesn't move
+	}{
+		{"/src/api", "/src/api.worktrees", "/new/api2"},
+		{"/src/api/sub/wt", "/src/api.worktrees", "/new/api2/sub/wt"},
+		{"/src/api.worktrees", "/src/api.worktrees", "/new/api2.worktrees"},
+		{"/src/api.worktrees/feature", "/src/api.worktrees", "/new/api2.worktrees/feature"},
+		{"/src/api.bak/x", "/src/api.worktrees", ""},
+		{"/src/api.old", "/src/api.worktrees", ""},
+		{"/src/apiary", "/src/api.worktrees", ""},
+		{"/src/api.worktrees-old/x", "/src/api.worktrees", ""},
+		{"/wt/api/feature", "/wt/api", ""}, // a worktrees dir elsewhere stays
+	}
+	for _, tt := range tests {
+		got, ok := relocatePath(tt.path, "/src/api", "/new/api2", tt.worktreesDir)
+		if ok != (tt.want != "") || got != tt.want {
+			t.Errorf("relocatePath(%q) = %q, %v, want %q", tt.path, got, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Restore the most recently removed worktree",
+	Long: `Restore the most recently removed worktree, like 'wt restore' with the
+newest snapshot that hasn't been restored yet.`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [id]",
+	Short: "Restore a removed worktree from its snapshot",
+	Long: `Restore a removed worktree from its snapshot. Without an id, lists the
+snapshots.
+
+Before removing a worktree, wt saves a snapshot of it: the commit that was
+checked out, all uncommitted changes including untracked files (but not
+ignored ones) and the stashes made on its branch. The commits are kept in
+the repository under refs/wt/snapshots/<id> and the changes are also saved
+as a patch in the wt data directory.
+
+Restoring recreates the worktree at its old path on its branch (recreating
+the branch if it was deleted), reapplies the changes as unstaged changes and
+puts back stashes that were dropped since. If the branch has moved on since,
+the changes are applied on top of its current commit.
+
+Snapshots are deleted after retention_days, or with 'wt gc --all'.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestore,
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	snap, err := db.LatestSnapshot(database)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	if snap == nil {
+		return fmt.Errorf("no removed worktree to restore")
+	}
+	return restoreSnapshot(database, snap)
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	if len(args) == 0 {
+		return listSnapshots(database)
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid snapshot id: %s", args[0])
+	}
+	snap, err := db.GetSnapshot(database, id)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	if snap == nil {
+		return fmt.Errorf("snapshot not found: %d", id)
+	}
+	return restoreSnapshot(database, snap)
+}
+
+// listSnapshots prints all snapshots, newest first
+func listSnapshots(database *sql.DB) error {
+	snapshots, err := db.ListSnapshots(database)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	if len(snapshots) == 0 {
+		fmt.Fprintln(os.Stderr, "No snapshots.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tWORKTREE\tREMOVED\tCHANGES\tPATH")
+	for _, s := range snapshots {
+		changes := "-"
+		if s.Dirty() {
+			changes = "uncommitted"
+		}
+		if len(s.Stashes) > 0 {
+			changes += fmt.Sprintf(", %d stash(es)", len(s.Stashes))
+		}
+		removed := ui.FormatAge(s.CreatedAt)
+		if s.RestoredAt != nil {
+			removed += " (restored)"
+		}
+		fmt.Fprintf(w, "%d\t%s/%s\t%s\t%s\t%s\n", s.ID, s.RepoName, s.Branch, removed, changes, s.Path)
+	}
+	return w.Flush()
+}
+
+// snapshotWorktree saves the state of a worktree before it is removed.
+// Returns nil if the worktree's directory is already gone.
+func snapshotWorktree(database *sql.DB, wt *db.Worktree) (*db.Snapshot, error) {
+	if _, err := os.Stat(wt.Path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	gs, err := git.TakeSnapshot(wt.Path, wt.Branch)
+	if err != nil {
+		return nil, err
+	}
+	snap := &db.Snapshot{
+		RepoID:  wt.RepoID,
+		Path:    wt.Path,
+		Branch:  wt.Branch,
+		Head:    gs.Head,
+		Commit:  gs.Commit,
+		Stashes: gs.Stashes,
+	}
+	if err := db.CreateSnapshot(database, snap); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	snap.RepoPath = wt.RepoPath
+
+	if err := keepSnapshot(snap, gs); err != nil {
+		return nil, errors.Join(err, discardSnapshot(database, snap))
+	}
+	return snap, nil
+}
+
+// keepSnapshot references the snapshot's commits from the repository and
+// archives its changes as a patch
+func keepSnapshot(snap *db.Snapshot, gs *git.Snapshot) error {
+	if err := git.UpdateRef(snap.RepoPath, snap.Ref(), snap.Commit); err != nil {
+		return err
+	}
+	for i, stash := range snap.Stashes {
+		if err := git.UpdateRef(snap.RepoPath, fmt.Sprintf("%s-stash-%d", snap.Ref(), i), stash); err != nil {
+			return err
+		}
+	}
+
+	if !snap.Dirty() {
+		return nil
+	}
+	patch, err := git.SnapshotPatch(snap.RepoPath, gs)
+	if err != nil {
+		return err
+	}
+	path, err := snapshotPatchPath(snap.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(patch), 0644)
+}
+
+// discardSnapshot deletes a snapshot with its refs and patch
+func discardSnapshot(database *sql.DB, snap *db.Snapshot) error {
+	var errs []error
+	refs := []string{snap.Ref()}
+	for i := range snap.Stashes {
+		refs = append(refs, fmt.Sprintf("%s-stash-%d", snap.Ref(), i))
+	}
+	for _, ref := range refs {
+		if git.RefExists(snap.RepoPath, ref) {
+			errs = append(errs, git.DeleteRef(snap.RepoPath, ref))
+		}
+	}
+	if path, err := snapshotPatchPath(snap.ID); err == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, db.DeleteSnapshot(database, snap.ID))
+	return errors.Join(errs...)
+}
+
+// snapshotPatchPath returns where a snapshot's changes are archived, in
+// the snapshots directory next to the database
+func snapshotPatchPath(id int64) (string, error) {
+	dbPath, err := db.DefaultPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dbPath), "snapshots", fmt.Sprintf("%d.patch", id)), nil
+}
+
+// restoreSnapshot recreates a removed worktree from its snapshot
+func restoreSnapshot(database *sql.DB, snap *db.Snapshot) error {
+	name := snap.RepoName + "/" + snap.Branch
+	if snap.RestoredAt != nil {
+		return fmt.Errorf("snapshot %d of %s was already restored", snap.ID, name)
+	}
+	repo, err := db.GetRepoByID(database, snap.RepoID)
+	if err != nil {
+		return fmt.Errorf("failed to get repo: %w", err)
+	}
+	if repo == nil {
+		return fmt.Errorf("%s is no longer tracked, add it back with 'wt repo add'", snap.RepoName)
+	}
+	if snap.Head == "" {
+		return fmt.Errorf("%s had no commits, so there is no worktree to restore", name)
+	}
+	if _, err := os.Stat(snap.Path); err == nil {
+		return fmt.Errorf("%s already exists", snap.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(snap.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	switch {
+	case snap.Branch == "(detached)":
+		err = git.AddDetachedWorktree(repo.Path, snap.Head, snap.Path)
+	case git.RefExists(repo.Path, "refs/heads/"+snap.Branch):
+		if tip, _ := git.ResolveRef(repo.Path, "refs/heads/"+snap.Branch); tip != snap.Head {
+			fmt.Fprintf(os.Stderr, "Branch %s has moved since it was removed, reapplying changes on top of it\n", snap.Branch)
+		}
+		err = git.AddWorktree(repo.Path, snap.Branch, "", snap.Path)
+	default:
+		err = git.AddWorktree(repo.Path, snap.Branch, snap.Head, snap.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to recreate worktree: %w", err)
+	}
+	if err := syncWorktrees(database, repo); err != nil {
+		return fmt.Errorf("failed to sync worktrees: %w", err)
+	}
+
+	if snap.Dirty() {
+		patch, err := snapshotPatchPath(snap.ID)
+		if err != nil {
+			return err
+		}
+		if err := git.ApplyPatch(snap.Path, patch); err != nil {
+			return fmt.Errorf("recreated the worktree, but failed to reapply its changes: %w\nThey are saved in %s and in commit %s", err, patch, snap.Commit)
+		}
+	}
+	if len(snap.Stashes) > 0 {
+		if n, err := git.RestoreStashes(repo.Path, snap.Stashes); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to restore stashes: %v\n", err)
+		} else if n > 0 {
+			fmt.Fprintf(os.Stderr, "Restored %d stash(es)\n", n)
+		}
+	}
+
+	if err := db.MarkSnapshotRestored(database, snap.ID); err != nil {
+		return fmt.Errorf("failed to update snapshot: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Restored %s at %s\n", name, snap.Path)
+	return nil
+}
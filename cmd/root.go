@@ -3,11 +3,15 @@ package cmd
 import (
 	"database/sql"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/roveo/wt/internal/config"
 	"github.com/roveo/wt/internal/db"
 	"github.com/roveo/wt/internal/git"
+	"github.com/roveo/wt/internal/shell"
 	"github.com/roveo/wt/internal/tmux"
 	"github.com/roveo/wt/internal/ui"
 	"github.com/spf13/cobra"
@@ -23,15 +27,22 @@ Run 'wt' without arguments to:
   - Show a fuzzy finder to switch between worktrees
   - Output a cd command for shell integration
 
+'wt <query>' is shorthand for 'wt switch <query>'.
+
 Setup shell integration by adding to your rc file:
   eval "$(wt init bash)"   # or zsh/fish`,
-	SilenceUsage:  true,
-	SilenceErrors: true,
-	RunE:          runRoot,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeSwitch,
+	RunE:              runRoot,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	registerRepoFlagCompletions(rootCmd)
+	err := rootCmd.Execute()
+	stopBackgroundScan()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -76,6 +87,29 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
+	globalCfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	sortMode, err := db.ParseSortMode(globalCfg.Sort)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	pickerOpts := ui.PickerOptions{Sort: sortMode, CurrentRepoPath: currentRepoPath}
+
+	// With a query, jump straight to an unambiguous match, otherwise
+	// let the user choose among the matches
+	if query := strings.Join(args, " "); query != "" {
+		if len(ui.MatchWorktrees(worktrees, query)) == 0 {
+			return fmt.Errorf("no worktree matches %q", query)
+		}
+		if wt, ok := ui.BestMatch(worktrees, query, currentRepoPath); ok {
+			outputWorktreeSwitch(wt)
+			return nil
+		}
+		pickerOpts.Query = query
+	}
+
 	// If no worktrees found, go directly to add workflow if we're in a repo
 	if len(worktrees) == 0 {
 		if !git.IsInsideRepo(cwd) {
@@ -89,10 +123,11 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	// Main loop - allows switching between worktree picker and add mode
 	for {
 		// Show picker
-		result, err := ui.PickWorktree(worktrees)
+		result, err := ui.PickWorktree(worktrees, pickerOpts)
 		if err != nil {
 			return err
 		}
+		pickerOpts.Query = ""
 
 		switch result.Action {
 		case ui.ActionNone:
@@ -120,11 +155,16 @@ func runRoot(cmd *cobra.Command, args []string) error {
 			// Otherwise (user completed add or cancelled), exit
 			return nil
 		case ui.ActionDelete:
-			// Delete the selected worktree
-			if result.Worktree == nil {
+			// Delete the selected worktree, or all marked ones at once
+			if len(result.Worktrees) == 0 {
 				continue
 			}
-			if err := deleteWorktree(database, result.Worktree); err != nil {
+			if len(result.Worktrees) == 1 {
+				err = deleteWorktree(database, result.Worktrees[0], globalCfg.Remove)
+			} else {
+				err = deleteWorktrees(database, result.Worktrees, globalCfg.Remove)
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			}
 			// Refresh worktree list and continue
@@ -136,6 +176,26 @@ func runRoot(cmd *cobra.Command, args []string) error {
 				return nil
 			}
 			continue
+		case ui.ActionRename:
+			wt := result.Worktree
+			target, ok, err := ui.InputText(fmt.Sprintf("Rename %s/%s to (new branch, or a path to move it):", wt.RepoName, wt.Branch), wt.Branch)
+			if err != nil {
+				return err
+			}
+			if ok && target != "" && target != wt.Branch {
+				if err := moveWorktree(database, wt, target, false); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				}
+			}
+			worktrees, err = db.ListAllWorktreesWithRepoFirst(database, currentRepoPath)
+			if err != nil {
+				return fmt.Errorf("failed to list worktrees: %w", err)
+			}
+			continue
+		case ui.ActionRun:
+			return runInWorktrees(result.Worktrees)
+		case ui.ActionOpen:
+			return openTmuxWindows(result.Worktrees)
 		}
 	}
 }
@@ -143,130 +203,114 @@ func runRoot(cmd *cobra.Command, args []string) error {
 // ensureCurrentRepoInDB ensures the current repository is added to the database
 // If inside a worktree, it finds and adds the main repository
 func ensureCurrentRepoInDB(database *sql.DB, cwd string) error {
+	_, _, err := trackRepo(database, cwd)
+	return err
+}
+
+// trackRepo adds the repository containing path to the database unless it's
+// already tracked. Returns the repo and whether it was newly added.
+func trackRepo(database *sql.DB, path string) (*db.Repo, bool, error) {
 	// Get main repo path (works from both main repo and worktrees)
-	mainRepoPath, err := git.GetMainRepoPath(cwd)
+	mainRepoPath, err := git.GetMainRepoPath(path)
 	if err != nil {
-		return fmt.Errorf("failed to get main repo path: %w", err)
+		return nil, false, fmt.Errorf("failed to get main repo path: %w", err)
 	}
 
 	// Check if repo exists in database
 	repo, err := db.GetRepoByPath(database, mainRepoPath)
 	if err != nil {
-		return fmt.Errorf("failed to check repo: %w", err)
+		return nil, false, fmt.Errorf("failed to check repo: %w", err)
+	}
+	if repo != nil {
+		return repo, false, nil
 	}
 
 	// If not in DB, add it
-	if repo == nil {
-		repo = &db.Repo{
-			Path:         mainRepoPath,
-			Name:         git.GetRepoName(mainRepoPath),
-			WorktreesDir: git.GetDefaultWorktreesDir(mainRepoPath),
-		}
-		if err := db.UpsertRepo(database, repo); err != nil {
-			return fmt.Errorf("failed to save repo: %w", err)
-		}
+	repo = &db.Repo{
+		Path:         mainRepoPath,
+		Name:         git.GetRepoName(mainRepoPath),
+		WorktreesDir: git.GetDefaultWorktreesDir(mainRepoPath),
 	}
-
-	return nil
+	if err := db.UpsertRepo(database, repo); err != nil {
+		return nil, false, fmt.Errorf("failed to save repo: %w", err)
+	}
+	return repo, true, nil
 }
 
-// syncAllRepos syncs worktrees for all repositories in the database
-func syncAllRepos(database *sql.DB) error {
-	repos, err := db.ListRepos(database)
+// loadConfig loads the global config and applies its key bindings and theme
+// to the pickers. Unlike commands that only read a setting or two, interactive
+// commands fail on an invalid config so mistakes in [keys] or [theme] don't go
+// unnoticed.
+func loadConfig() (config.Config, error) {
+	cfg, err := config.Load()
 	if err != nil {
-		return fmt.Errorf("failed to list repos: %w", err)
+		return cfg, fmt.Errorf("invalid config: %w", err)
 	}
-
-	for _, repo := range repos {
-		if err := syncWorktrees(database, repo); err != nil {
-			// Log error but continue with other repos
-			fmt.Fprintf(os.Stderr, "warning: failed to sync %s: %v\n", repo.Name, err)
-			continue
-		}
-		if err := db.UpdateLastSynced(database, repo.ID); err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to update sync timestamp for %s: %v\n", repo.Name, err)
-		}
-	}
-
-	return nil
+	ui.SetKeys(cfg.Keys)
+	ui.SetTheme(cfg.Theme)
+	return cfg, nil
 }
 
-// deleteWorktree deletes a worktree with confirmation
-func deleteWorktree(database *sql.DB, wt *db.Worktree) error {
+// deleteWorktree deletes a worktree after a confirmation that lists its
+// uncommitted files, unpushed commits and stashes, and offers to delete its
+// branch. Losing uncommitted changes or unpushed commits takes typing the
+// branch name.
+func deleteWorktree(database *sql.DB, wt *db.Worktree, cfg config.RemoveConfig) error {
 	if wt.IsMain {
 		return fmt.Errorf("cannot delete the main worktree")
 	}
-
-	// Confirm deletion
-	confirmed, err := ui.Confirm(fmt.Sprintf("Delete worktree %s/%s?", wt.RepoName, wt.Branch))
-	if err != nil {
+	if err := checkNotInUse(wt); err != nil {
 		return err
 	}
-	if !confirmed {
-		return nil
-	}
-
-	// Remove from git
-	fmt.Fprintf(os.Stderr, "Removing worktree...\n")
-	if err := git.RemoveWorktree(wt.RepoPath, wt.Path); err != nil {
-		// Try force remove
-		if err := git.RemoveWorktreeForce(wt.RepoPath, wt.Path); err != nil {
-			return fmt.Errorf("failed to remove worktree: %w", err)
-		}
-	}
-
-	// Clean up tmux window if it exists
-	cleanupTmuxWindow(wt)
-
-	// Soft-delete from database
-	if err := db.SoftDeleteWorktree(database, wt.ID); err != nil {
-		return fmt.Errorf("failed to update database: %w", err)
-	}
-
-	fmt.Fprintf(os.Stderr, "Worktree deleted.\n")
-	return nil
-}
-
-// syncWorktrees syncs the worktrees for a repository
-func syncWorktrees(database *sql.DB, repo *db.Repo) error {
-	// Get worktrees from git
-	gitWorktrees, err := git.ListWorktrees(repo.Path)
+	check, err := checkRemoval(wt)
 	if err != nil {
 		return err
 	}
 
-	// Upsert each worktree
-	var existingPaths []string
-	for _, gwt := range gitWorktrees {
-		wt := &db.Worktree{
-			RepoID: repo.ID,
-			Path:   gwt.Path,
-			Branch: gwt.Branch,
-			IsMain: gwt.IsMain,
+	plan := planBranch(wt)
+	title := fmt.Sprintf("Delete worktree %s/%s?", wt.RepoName, wt.Branch)
+
+	fmt.Fprintf(os.Stderr, "Worktree %s/%s at %s\n", wt.RepoName, wt.Branch, wt.Path)
+	printRemovalCheck(check, plan.keep != "", "")
+	if plan.keep == "" {
+		// The branch options double as the confirmation
+		confirmed, err := pickBranchDeletion(title, []*branchPlan{plan}, cfg)
+		if err != nil || !confirmed {
+			return err
 		}
-		if err := db.UpsertWorktree(database, wt); err != nil {
+		printBranchPlan(plan, "")
+	}
+	if destructive := check.Destructive() || plan.destructive(check); destructive || plan.keep != "" {
+		confirmed, err := confirmRemoval(title, destructive, wt.Branch)
+		if err != nil {
 			return err
 		}
-		existingPaths = append(existingPaths, gwt.Path)
+		if !confirmed {
+			return nil
+		}
 	}
 
-	// Soft-delete worktrees that no longer exist
-	if err := db.SoftDeleteMissingWorktrees(database, repo.ID, existingPaths); err != nil {
+	fmt.Fprintf(os.Stderr, "Removing worktree...\n")
+	if err := removeTrackedWorktree(database, wt, forceRemoval(check), snapshotAsk); err != nil {
 		return err
 	}
 
+	fmt.Fprintf(os.Stderr, "Worktree deleted.\n")
+	deleteWorktreeBranch(wt.RepoPath, plan)
 	return nil
 }
 
 // outputWorktreeSwitch handles switching to a worktree, either via cd or tmux
 func outputWorktreeSwitch(wt *db.Worktree) {
+	recordAccess(wt)
+
 	globalCfg, _ := config.Load()
 	projectCfg, _ := config.LoadProject(wt.RepoPath)
 	onEnter := projectCfg.OnEnter
 
 	// If tmux mode is disabled, just output cd + on_enter
 	if globalCfg.Tmux.Mode != "window" {
-		outputCdCommands(wt.Path, onEnter)
+		outputCdCommands(wt.Path, projectCfg)
 		return
 	}
 
@@ -279,7 +323,7 @@ func outputWorktreeSwitch(wt *db.Worktree) {
 		if !tmux.SessionExists(session) {
 			if err := tmux.CreateSession(session); err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to create tmux session: %v\n", err)
-				outputCdCommands(wt.Path, onEnter)
+				outputCdCommands(wt.Path, projectCfg)
 				return
 			}
 		}
@@ -289,14 +333,16 @@ func outputWorktreeSwitch(wt *db.Worktree) {
 			if !tmux.WindowExists(session, windowName) {
 				if err := tmux.CreateWindow(session, windowName, wt.Path, onEnter); err != nil {
 					fmt.Fprintf(os.Stderr, "Warning: failed to create tmux window: %v\n", err)
-					outputCdCommands(wt.Path, onEnter)
+					outputCdCommands(wt.Path, projectCfg)
 					return
 				}
 			} else {
 				// Window exists, just switch to it
 				tmux.SwitchToWindow(session, windowName)
 			}
-			fmt.Printf("tmux attach -t %s\n", session)
+			d := shell.NewDirectives()
+			d.Attach(session)
+			flushDirectives(d)
 			return
 		}
 
@@ -308,7 +354,7 @@ func outputWorktreeSwitch(wt *db.Worktree) {
 		}
 	} else if !tmux.InTmux() {
 		// No dedicated session and not in tmux - just cd
-		outputCdCommands(wt.Path, onEnter)
+		outputCdCommands(wt.Path, projectCfg)
 		return
 	}
 
@@ -330,40 +376,54 @@ func outputWorktreeSwitch(wt *db.Worktree) {
 	// No stdout output - tmux handled everything
 }
 
-// outputCdCommands outputs cd and on_enter commands for shell evaluation
-func outputCdCommands(path, onEnter string) {
-	fmt.Printf("cd %q\n", path)
-	if onEnter != "" {
-		fmt.Println(onEnter)
+// recordAccess logs a switch to the worktree for frecency ordering
+func recordAccess(wt *db.Worktree) {
+	database, err := db.Default()
+	if err != nil {
+		return // Not critical
 	}
-}
 
-// cleanupTmuxWindow kills the tmux window associated with a worktree if it exists
-func cleanupTmuxWindow(wt *db.Worktree) {
-	// Load global config
-	globalCfg, err := config.Load()
-	if err != nil {
-		return // Silently fail - not critical
+	// Worktrees built after an add have no ID yet, so look them up
+	id := wt.ID
+	if id == 0 {
+		stored, err := db.GetWorktreeByPath(database, wt.Path)
+		if err != nil || stored == nil {
+			return
+		}
+		id = stored.ID
 	}
 
-	// Check if tmux mode is enabled
-	if globalCfg.Tmux.Mode != "window" {
-		return // Tmux integration disabled
+	if err := db.RecordAccess(database, id); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record worktree access: %v\n", err)
 	}
+}
 
-	// Determine target session
-	var targetSession string
-	if globalCfg.Tmux.Session != "" {
-		targetSession = globalCfg.Tmux.Session
-	} else if tmux.InTmux() {
-		targetSession = tmux.CurrentSession()
-	} else {
-		return // Not in tmux and no dedicated session configured
+// outputCdCommands tells the shell wrapper to cd into path, export the
+// project's env vars and run on_enter
+func outputCdCommands(path string, projectCfg config.ProjectConfig) {
+	d := shell.NewDirectives()
+	d.Chdir(path)
+	for _, name := range slices.Sorted(maps.Keys(projectCfg.Env)) {
+		if err := d.Setenv(name, projectCfg.Env[name]); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
 	}
+	d.Run(projectCfg.OnEnter)
+	flushDirectives(d)
+}
 
-	// Check if session exists
-	if !tmux.SessionExists(targetSession) {
-		return // Session doesn't exist
+// flushDirectives hands directives to the shell wrapper
+func flushDirectives(d *shell.Directives) {
+	if err := d.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// cleanupTmuxWindow kills the tmux window associated with a worktree if it exists
+func cleanupTmuxWindow(wt *db.Worktree) {
+	targetSession, ok := worktreeWindowSession()
+	if !ok {
+		return
 	}
 
 	// Build window name
@@ -384,3 +444,34 @@ func cleanupTmuxWindow(wt *db.Worktree) {
 		fmt.Fprintf(os.Stderr, "Warning: failed to kill tmux window '%s': %v\n", windowName, err)
 	}
 }
+
+// worktreeWindowSession returns the tmux session that holds worktree windows,
+// or false if tmux integration is disabled or the session isn't running
+func worktreeWindowSession() (string, bool) {
+	// Load global config
+	globalCfg, err := config.Load()
+	if err != nil {
+		return "", false // Silently fail - not critical
+	}
+
+	// Check if tmux mode is enabled
+	if globalCfg.Tmux.Mode != "window" {
+		return "", false // Tmux integration disabled
+	}
+
+	// Determine target session
+	var targetSession string
+	if globalCfg.Tmux.Session != "" {
+		targetSession = globalCfg.Tmux.Session
+	} else if tmux.InTmux() {
+		targetSession = tmux.CurrentSession()
+	} else {
+		return "", false // Not in tmux and no dedicated session configured
+	}
+
+	// Check if session exists
+	if !tmux.SessionExists(targetSession) {
+		return "", false // Session doesn't exist
+	}
+	return targetSession, true
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/roveo/wt/internal/config"
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// scanInterval is how long a root is left alone between scans on sync
+const scanInterval = time.Hour
+
+var scanDepth int
+
+var scanCmd = &cobra.Command{
+	Use:   "scan [dir...]",
+	Short: "Find and track repositories under the scan roots",
+	Long: `Find git repositories under the given directories, or the [scan] roots
+from the config, and start tracking them along with all their worktrees,
+including ones created outside wt.
+
+Directories matching [scan] ignore are skipped, as are repos removed with
+'wt repo rm' (add them back with 'wt repo add'). Set [scan] on_sync = true
+to scan one root per sync in the background of normal use.`,
+	RunE: runScan,
+}
+
+func init() {
+	scanCmd.Flags().IntVar(&scanDepth, "depth", -1, "Directory levels to search below each root (default: [scan] depth)")
+	rootCmd.AddCommand(scanCmd)
+}
+
+func runScan(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if scanDepth >= 0 {
+		cfg.Scan.Depth = scanDepth
+	}
+
+	roots, err := cfg.Scan.ScanRoots()
+	if err != nil {
+		return fmt.Errorf("failed to resolve scan roots: %w", err)
+	}
+	if len(args) > 0 {
+		roots = nil
+		for _, arg := range args {
+			root, err := filepath.Abs(arg)
+			if err != nil {
+				return fmt.Errorf("invalid path: %w", err)
+			}
+			roots = append(roots, root)
+		}
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("nothing to scan: pass directories or set [scan] roots in the config")
+	}
+
+	database, err := db.Default()
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+
+	found, added, err := scanRepos(context.Background(), database, roots, cfg.Scan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, repo := range added {
+		fmt.Fprintf(os.Stderr, "  + %s (%s)\n", repo.Name, repo.Path)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d repo(s) in %d root(s), %d new.\n", found, len(roots), len(added))
+
+	// Lists the worktrees of the new repos
+	return syncAllRepos(database)
+}
+
+// scanRepos finds repos under roots and tracks the ones that aren't yet,
+// skipping repos that were removed. Returns the number of repos found and
+// the newly tracked ones. Scan errors are returned alongside the results.
+// The roots are only recorded as scanned if the walk wasn't cut short by
+// ctx, so an unfinished root is scanned again.
+func scanRepos(ctx context.Context, database *sql.DB, roots []string, cfg config.ScanConfig) (int, []*db.Repo, error) {
+	paths, scanErr := git.FindRepos(ctx, roots, cfg.Depth, cfg.Ignore)
+
+	known, err := db.ListRepoPaths(database)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	var added []*db.Repo
+	for _, path := range paths {
+		if _, ok := known[path]; ok {
+			continue
+		}
+		repo, _, err := trackRepo(database, path)
+		if err != nil {
+			return len(paths), added, err
+		}
+		added = append(added, repo)
+	}
+
+	if ctx.Err() != nil {
+		return len(paths), added, scanErr
+	}
+	for _, root := range roots {
+		if err := db.RecordScan(database, root); err != nil {
+			return len(paths), added, fmt.Errorf("failed to record scan: %w", err)
+		}
+	}
+	return len(paths), added, scanErr
+}
+
+// stopBackgroundScan cuts off the scan started by scanOnSync, if any, and
+// waits for it to finish writing
+var stopBackgroundScan = func() {}
+
+// scanOnSync starts scanning the configured root that was scanned longest
+// ago in the background, if on_sync is set and the root is due. The scan
+// runs while wt does its work, e.g. while the picker is open, and is cut
+// off before the next transaction and before wt exits; an unfinished root
+// is picked again next time. Repos it tracks show up from the next run,
+// and nothing is printed, as the picker may be on screen.
+func scanOnSync(database *sql.DB, cfg config.ScanConfig) {
+	if !cfg.OnSync {
+		return
+	}
+	roots, err := cfg.ScanRoots()
+	if err != nil || len(roots) == 0 {
+		return
+	}
+	times, err := db.ListScanTimes(database)
+	if err != nil {
+		return
+	}
+
+	root := roots[0]
+	for _, r := range roots[1:] {
+		if times[r].Before(times[root]) {
+			root = r
+		}
+	}
+	if time.Since(times[root]) < scanInterval {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanRepos(ctx, database, []string{root}, cfg)
+	}()
+	stopBackgroundScan = func() {
+		cancel()
+		<-done
+		stopBackgroundScan = func() {}
+	}
+}
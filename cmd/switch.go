@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var switchCmd = &cobra.Command{
+	Use:   "switch <query>",
+	Short: "Jump to the worktree matching a query",
+	Long: `Jump to the worktree best matching a query, like zoxide's z.
+
+The query is fuzzy-matched against repo/branch and path, the same way the
+picker filters. If exactly one worktree matches, or the query is exactly a
+repo/branch, branch or directory name, wt switches to it directly.
+Otherwise the picker opens pre-filled with the query.
+
+'wt <query>' is shorthand for 'wt switch <query>'.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeSwitch,
+	RunE:              runRoot,
+}
+
+func init() {
+	rootCmd.AddCommand(switchCmd)
+}
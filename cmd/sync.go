@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roveo/wt/internal/config"
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+)
+
+const (
+	// syncWorkers is the number of repos listed concurrently
+	syncWorkers = 8
+
+	// syncTimeout bounds how long a single repo may take to list, so an
+	// unreachable repo (e.g. on an unmounted drive) can't block the picker
+	syncTimeout = 2 * time.Second
+)
+
+// syncResync forces listing every repo, ignoring sync fingerprints
+var syncResync bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&syncResync, "resync", false, "Re-list worktrees of all repos, ignoring the sync cache")
+}
+
+// repoListing is the result of listing the worktrees of one repo
+type repoListing struct {
+	repo        *db.Repo
+	worktrees   []git.WorktreeInfo
+	fingerprint string
+	unchanged   bool // fingerprint matches the last sync, worktrees weren't listed
+	err         error
+}
+
+// syncAllRepos syncs worktrees for all repositories in the database.
+// Repos are listed in parallel and the results written in one transaction;
+// repos that fail are skipped and reported in a single warning. Repos whose
+// fingerprint hasn't changed since the last sync are skipped unless --resync.
+// With [scan] on_sync, a scan root may then be scanned for new repos in the
+// background.
+// Repos whose path is gone are marked missing, and removed rows older than
+// retention_days are purged.
+func syncAllRepos(database *sql.DB) error {
+	// Config errors are reported by the commands that use it
+	cfg, _ := config.Load()
+	// Its writes would make the transaction's fail
+	stopBackgroundScan()
+
+	repos, err := db.ListRepos(database)
+	if err != nil {
+		return fmt.Errorf("failed to list repos: %w", err)
+	}
+
+	listings := listAllWorktrees(repos)
+
+	// Outside the transaction, which would block its writes
+	if cfg.RetentionDays > 0 {
+		if err := discardPurgeableSnapshots(database, cfg.RetentionDays); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start sync: %w", err)
+	}
+	defer tx.Rollback()
+
+	var failed []string
+	for _, l := range listings {
+		// Unreachable isn't missing: only a path that is gone counts
+		if missing := errors.Is(l.err, os.ErrNotExist); missing != (l.repo.MissingAt != nil) {
+			if err := db.SetRepoMissing(tx, l.repo.ID, missing); err != nil {
+				return fmt.Errorf("failed to save sync results: %w", err)
+			}
+		}
+		if l.unchanged {
+			// Nothing to save, but it was checked
+			if err := db.UpdateLastSynced(tx, l.repo.ID); err != nil {
+				return fmt.Errorf("failed to save sync results: %w", err)
+			}
+			continue
+		}
+		if l.err == nil {
+			l.err = saveWorktrees(tx, l.repo, l.worktrees)
+		}
+		if l.err == nil {
+			l.err = db.UpdateLastSynced(tx, l.repo.ID)
+		}
+		if l.err == nil {
+			l.err = db.UpdateSyncFingerprint(tx, l.repo.ID, l.fingerprint)
+		}
+		if l.err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", l.repo.Name, syncErrorReason(l.err)))
+		}
+	}
+
+	if cfg.RetentionDays > 0 {
+		if _, _, err := db.PurgeDeleted(tx, cfg.RetentionDays); err != nil {
+			return fmt.Errorf("failed to purge removed worktrees: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save sync results: %w", err)
+	}
+	scanOnSync(database, cfg.Scan)
+
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: skipped %d repo(s): %s\n", len(failed), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// listAllWorktrees lists the worktrees of all repos using a bounded worker
+// pool. Results are returned in the order of repos.
+func listAllWorktrees(repos []*db.Repo) []repoListing {
+	listings := make([]repoListing, len(repos))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(syncWorkers, len(repos)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				listings[i] = listRepoWorktrees(repos[i])
+			}
+		}()
+	}
+
+	for i := range repos {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return listings
+}
+
+// listRepoWorktrees lists the worktrees of a repo unless its fingerprint
+// is unchanged, giving up after syncTimeout. The file system access runs in
+// the background too, since it is what blocks on a hung network mount.
+func listRepoWorktrees(repo *db.Repo) repoListing {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	done := make(chan repoListing, 1)
+	go func() {
+		listing := repoListing{repo: repo}
+		if _, err := os.Stat(repo.Path); err != nil {
+			listing.err = err
+			done <- listing
+			return
+		}
+
+		// Computed before listing, so a change in between is caught next time.
+		// On error the fingerprint stays empty and the repo is always listed.
+		listing.fingerprint, _ = git.WorktreesFingerprint(repo.Path)
+		if !syncResync && listing.fingerprint != "" && listing.fingerprint == repo.SyncFingerprint {
+			listing.unchanged = true
+			done <- listing
+			return
+		}
+
+		listing.worktrees, listing.err = git.ListWorktreesContext(ctx, repo.Path)
+		done <- listing
+	}()
+
+	select {
+	case listing := <-done:
+		return listing
+	case <-ctx.Done():
+		return repoListing{repo: repo, err: ctx.Err()}
+	}
+}
+
+// syncErrorReason returns a short description of why a repo failed to sync
+func syncErrorReason(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	case errors.Is(err, os.ErrNotExist):
+		return "not found"
+	}
+	reason, _, _ := strings.Cut(err.Error(), "\n")
+	return reason
+}
+
+// syncWorktrees syncs the worktrees for a repository and records its fingerprint
+func syncWorktrees(database db.DBTX, repo *db.Repo) error {
+	fingerprint, _ := git.WorktreesFingerprint(repo.Path)
+	gitWorktrees, err := git.ListWorktrees(repo.Path)
+	if err != nil {
+		return err
+	}
+	if err := saveWorktrees(database, repo, gitWorktrees); err != nil {
+		return err
+	}
+	if err := db.UpdateLastSynced(database, repo.ID); err != nil {
+		return err
+	}
+	return db.UpdateSyncFingerprint(database, repo.ID, fingerprint)
+}
+
+// saveWorktrees upserts the worktrees git reported for repo and
+// soft-deletes the ones that no longer exist
+func saveWorktrees(database db.DBTX, repo *db.Repo, gitWorktrees []git.WorktreeInfo) error {
+	// Upsert each worktree
+	var existingPaths []string
+	for _, gwt := range gitWorktrees {
+		wt := &db.Worktree{
+			RepoID: repo.ID,
+			Path:   gwt.Path,
+			Branch: gwt.Branch,
+			IsMain: gwt.IsMain,
+		}
+		if err := db.UpsertWorktree(database, wt); err != nil {
+			return err
+		}
+		existingPaths = append(existingPaths, gwt.Path)
+	}
+
+	// Soft-delete worktrees that no longer exist
+	return db.SoftDeleteMissingWorktrees(database, repo.ID, existingPaths)
+}
@@ -1,19 +1,46 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 // Config represents the global wt configuration
 type Config struct {
-	// WorktreesDir is the directory pattern for storing worktrees.
-	// Defaults to "../{repo_name}.worktrees" (sibling to main repo).
+	// WorktreesDir is the path template for new worktrees. Supports the
+	// {repo_name}, {branch}, {branch_slug}, {user}, {home} and {remote_owner}
+	// placeholders (see ExpandTemplate).
+	// Empty means use the repo's stored directory, "../{repo_name}.worktrees".
 	WorktreesDir string `toml:"worktrees_dir"`
 
+	// Sort is the worktree order in the picker and `wt list`.
+	// "name" - current repo first, then alphabetical (default)
+	// "frecency" - most frequently and recently used first
+	// "recent" - most recently used first
+	Sort string `toml:"sort"`
+
+	// RetentionDays is how long removed worktrees and repos are kept in the
+	// database (with their history) before being purged. 0 keeps them.
+	RetentionDays int `toml:"retention_days"`
+
 	Tmux TmuxConfig `toml:"tmux"`
+
+	// Keys maps picker actions to keys, overriding the defaults per action.
+	// Conflicts are reported when the config is loaded.
+	Keys KeyBindings `toml:"keys"`
+
+	Theme ThemeConfig `toml:"theme"`
+
+	Scan ScanConfig `toml:"scan"`
+
+	Remove RemoveConfig `toml:"remove"`
 }
 
 // TmuxConfig holds tmux-related settings
@@ -29,14 +56,76 @@ type TmuxConfig struct {
 	Session string `toml:"session"`
 }
 
+// ThemeConfig holds the colors of the pickers. Each color is an ANSI color
+// number ("0"-"255") or a hex color ("#5fafd7"); empty uses the terminal's
+// default foreground. Colors are dropped when NO_COLOR is set.
+type ThemeConfig struct {
+	Selected string `toml:"selected"` // cursor row and titles
+	Match    string `toml:"match"`    // characters matched by the filter
+	Prompt   string `toml:"prompt"`   // input prompt
+	Help     string `toml:"help"`     // help line and details; dimmed if empty
+	Main     string `toml:"main"`     // [main] marker
+	Mark     string `toml:"mark"`     // multi-select marker
+}
+
+// ScanConfig controls where `wt scan` looks for repositories
+type ScanConfig struct {
+	// Roots are the directories to scan. A leading "~" expands to the
+	// home directory.
+	Roots []string `toml:"roots"`
+
+	// Depth is how many directory levels below a root repos are found at
+	Depth int `toml:"depth"`
+
+	// Ignore lists globs of directories to skip, matched against the
+	// directory name and its full path
+	Ignore []string `toml:"ignore"`
+
+	// OnSync scans one root per sync in the background, the one scanned
+	// longest ago, once it hasn't been scanned for an hour
+	OnSync bool `toml:"on_sync"`
+}
+
+// DeleteBranchModes are the values of [remove] delete_branch
+var DeleteBranchModes = []string{"never", "merged", "always"}
+
+// RemoveConfig controls what happens to the branch of a removed worktree,
+// in `wt remove` and the picker's delete dialog
+type RemoveConfig struct {
+	// DeleteBranch is when to delete the local branch.
+	// "never" - keep it (default)
+	// "merged" - if it is merged into the default branch, including squash merges
+	// "always" - even if it has commits that are not merged
+	DeleteBranch string `toml:"delete_branch"`
+
+	// DeleteRemoteBranch also deletes the branch's upstream on the remote
+	// when the local branch is deleted
+	DeleteRemoteBranch bool `toml:"delete_remote_branch"`
+}
+
 // DefaultConfig returns a Config with sensible defaults
 func DefaultConfig() Config {
 	return Config{
-		WorktreesDir: "../{repo_name}.worktrees",
+		Sort:          "name",
+		RetentionDays: 30,
 		Tmux: TmuxConfig{
 			Mode:    "disabled",
 			Session: "",
 		},
+		Keys: DefaultKeyBindings(),
+		Theme: ThemeConfig{
+			Selected: "6", // cyan
+			Match:    "6",
+			Prompt:   "6",
+			Mark:     "5", // magenta
+		},
+		Scan: ScanConfig{
+			Depth:  3,
+			Ignore: []string{".*", "node_modules", "vendor"},
+		},
+		Remove: RemoveConfig{
+			DeleteBranch: "never",
+		},
 	}
 }
 
@@ -63,13 +152,100 @@ func LoadFrom(path string) (Config, error) {
 		return cfg, err
 	}
 
+	// Decode keys on their own so defaults are merged per action
+	cfg.Keys = nil
 	if err := toml.Unmarshal(data, &cfg); err != nil {
+		cfg.Keys = DefaultKeyBindings()
+		return cfg, err
+	}
+
+	keys, err := resolveKeys(cfg.Keys)
+	if err != nil {
+		cfg.Keys = DefaultKeyBindings()
+		return cfg, err
+	}
+	cfg.Keys = keys
+
+	if err := validateTheme(cfg.Theme); err != nil {
+		cfg.Theme = DefaultConfig().Theme
 		return cfg, err
 	}
 
+	if days := cfg.RetentionDays; days < 0 {
+		cfg.RetentionDays = DefaultConfig().RetentionDays
+		return cfg, fmt.Errorf("retention_days: must not be negative, got %d", days)
+	}
+
+	if err := validateScan(cfg.Scan); err != nil {
+		cfg.Scan = DefaultConfig().Scan
+		return cfg, err
+	}
+
+	if mode := cfg.Remove.DeleteBranch; !slices.Contains(DeleteBranchModes, mode) {
+		cfg.Remove = DefaultConfig().Remove
+		return cfg, fmt.Errorf("[remove] delete_branch: must be one of %s, got %q", strings.Join(DeleteBranchModes, ", "), mode)
+	}
+
 	return cfg, nil
 }
 
+var hexColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validateTheme checks that every theme color is an ANSI number or hex color
+func validateTheme(theme ThemeConfig) error {
+	colors := []struct{ name, value string }{
+		{"selected", theme.Selected},
+		{"match", theme.Match},
+		{"prompt", theme.Prompt},
+		{"help", theme.Help},
+		{"main", theme.Main},
+		{"mark", theme.Mark},
+	}
+	for _, c := range colors {
+		if c.value == "" || hexColorRe.MatchString(c.value) {
+			continue
+		}
+		if n, err := strconv.Atoi(c.value); err == nil && n >= 0 && n <= 255 {
+			continue
+		}
+		return fmt.Errorf("[theme] %s: invalid color %q (use 0-255 or #rrggbb)", c.name, c.value)
+	}
+	return nil
+}
+
+// validateScan checks the scan depth and ignore globs
+func validateScan(scan ScanConfig) error {
+	if scan.Depth < 0 {
+		return fmt.Errorf("[scan] depth: must not be negative, got %d", scan.Depth)
+	}
+	for _, pattern := range scan.Ignore {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("[scan] ignore: invalid glob %q", pattern)
+		}
+	}
+	return nil
+}
+
+// ScanRoots returns the scan roots as absolute paths
+func (s ScanConfig) ScanRoots() ([]string, error) {
+	var roots []string
+	for _, root := range s.Roots {
+		if root == "~" || strings.HasPrefix(root, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("cannot expand ~ without a home directory")
+			}
+			root = filepath.Join(home, strings.TrimPrefix(root, "~"))
+		}
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, abs)
+	}
+	return roots, nil
+}
+
 // DefaultPath returns the default config file path
 func DefaultPath() (string, error) {
 	configDir := os.Getenv("XDG_CONFIG_HOME")
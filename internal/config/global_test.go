@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{"ansi and hex", "[theme]\nselected = \"4\"\nmatch = \"#d75f00\"\nhelp = \"#888\"\n", false},
+		{"out of range", "[theme]\nselected = \"256\"\n", true},
+		{"color name", "[theme]\nmain = \"red\"\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadFrom(path)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "[theme]") {
+					t.Fatalf("got error %v, want a [theme] error", err)
+				}
+				if cfg.Theme != DefaultConfig().Theme {
+					t.Errorf("invalid theme should fall back to defaults, got %+v", cfg.Theme)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Unset colors keep their defaults
+			if cfg.Theme.Prompt != "6" || cfg.Theme.Match != "#d75f00" {
+				t.Errorf("theme = %+v", cfg.Theme)
+			}
+		})
+	}
+}
+
+func TestLoadScan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[scan]\nroots = [\"~/src\"]\nignore = [\"[\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFrom(path)
+	if err == nil || !strings.Contains(err.Error(), "[scan] ignore") {
+		t.Fatalf("got error %v, want a [scan] ignore error", err)
+	}
+	if cfg.Scan.Depth != 3 || len(cfg.Scan.Roots) != 0 {
+		t.Errorf("invalid scan config should fall back to defaults, got %+v", cfg.Scan)
+	}
+}
+
+func TestLoadRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[remove]\ndelete_branch = \"merged\"\ndelete_remote_branch = true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Remove.DeleteBranch != "merged" || !cfg.Remove.DeleteRemoteBranch {
+		t.Errorf("got %+v", cfg.Remove)
+	}
+
+	if err := os.WriteFile(path, []byte("[remove]\ndelete_branch = \"yes\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = LoadFrom(path)
+	if err == nil || !strings.Contains(err.Error(), "[remove] delete_branch") {
+		t.Fatalf("got error %v, want a [remove] delete_branch error", err)
+	}
+	if cfg.Remove.DeleteBranch != "never" {
+		t.Errorf("invalid remove config should fall back to defaults, got %+v", cfg.Remove)
+	}
+}
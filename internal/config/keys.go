@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeyBindings maps picker action names to the keys that trigger them.
+// Keys use bubbletea's names ("enter", "ctrl+d", "alt+x", "a"); "ctrl-d"
+// style and "space" are accepted in the config file and normalized.
+type KeyBindings map[string]KeyList
+
+// KeyList is one or more keys. In TOML it can be a string or an array,
+// and an empty array unbinds the action.
+type KeyList []string
+
+// UnmarshalTOML implements toml.Unmarshaler
+func (k *KeyList) UnmarshalTOML(data any) error {
+	switch v := data.(type) {
+	case string:
+		*k = KeyList{v}
+	case []any:
+		keys := make(KeyList, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("keys must be strings, got %T", item)
+			}
+			keys = append(keys, s)
+		}
+		*k = keys
+	default:
+		return fmt.Errorf("keys must be a string or an array of strings, got %T", data)
+	}
+	return nil
+}
+
+// KeyContexts lists the actions of each picker in help line order. A key
+// can be bound to only one action per context.
+var KeyContexts = map[string][]string{
+	"picker": {"switch", "mark", "mark_all", "add", "rename", "delete", "run", "tmux", "preview", "sort", "quit", "up", "down"},
+	"branch": {"switch", "base", "fetch", "back", "quit", "up", "down"},
+}
+
+// DefaultKeyBindings returns the built-in key bindings
+func DefaultKeyBindings() KeyBindings {
+	return KeyBindings{
+		"switch":   {"enter"},
+		"mark":     {"tab", " "},
+		"mark_all": {"ctrl+a"},
+		"add":      {"ctrl+t"},
+		"rename":   {"ctrl+r"},
+		"delete":   {"ctrl+d"},
+		"run":      {"ctrl+x"},
+		"tmux":     {"ctrl+g"},
+		"preview":  {"ctrl+o"},
+		"sort":     {"ctrl+s"},
+		"quit":     {"esc"},
+		"up":       {"up", "ctrl+p"},
+		"down":     {"down", "ctrl+n"},
+		"base":     {"ctrl+b"},
+		"fetch":    {"ctrl+f"},
+		"back":     {"ctrl+t", "tab"},
+	}
+}
+
+// keyNames are the named keys accepted besides single characters and
+// ctrl+<letter>
+var keyNames = []string{
+	"enter", "tab", "shift+tab", "esc", "backspace", "delete", "insert",
+	"up", "down", "left", "right", "home", "end", "pgup", "pgdown",
+	"ctrl+up", "ctrl+down", "ctrl+left", "ctrl+right", "ctrl+home", "ctrl+end",
+	"shift+up", "shift+down", "shift+left", "shift+right", "shift+home", "shift+end",
+	"f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11", "f12",
+}
+
+// NormalizeKey converts a key as written in the config to bubbletea's name
+// for it, e.g. "Ctrl-D" to "ctrl+d" and "space" to " "
+func NormalizeKey(key string) (string, error) {
+	k := key
+	alt := false
+	if lower := strings.ToLower(k); strings.HasPrefix(lower, "alt-") || strings.HasPrefix(lower, "alt+") {
+		alt = true
+		k = k[4:]
+	}
+
+	// Single characters are case-sensitive, named keys and modifiers aren't
+	if utf8.RuneCountInString(k) != 1 {
+		k = strings.ToLower(k)
+		k = strings.Replace(k, "ctrl-", "ctrl+", 1)
+		k = strings.Replace(k, "shift-", "shift+", 1)
+		switch k {
+		case "space":
+			k = " "
+		case "return":
+			k = "enter"
+		case "escape":
+			k = "esc"
+		}
+	}
+
+	valid := utf8.RuneCountInString(k) == 1 ||
+		slices.Contains(keyNames, k) ||
+		(len(k) == 6 && strings.HasPrefix(k, "ctrl+") && k[5] >= 'a' && k[5] <= 'z')
+	if !valid {
+		return "", fmt.Errorf("unknown key %q", key)
+	}
+	if k == "ctrl+c" {
+		return "", fmt.Errorf("ctrl+c always quits and can't be bound")
+	}
+	if alt {
+		k = "alt+" + k
+	}
+	return k, nil
+}
+
+// resolveKeys normalizes configured bindings, fills in defaults for
+// actions that aren't configured and checks for conflicts
+func resolveKeys(configured KeyBindings) (KeyBindings, error) {
+	resolved := DefaultKeyBindings()
+	for action, keys := range configured {
+		if _, ok := resolved[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q in [keys]", action)
+		}
+		normalized := make(KeyList, 0, len(keys))
+		for _, key := range keys {
+			k, err := NormalizeKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("[keys] %s: %w", action, err)
+			}
+			normalized = append(normalized, k)
+		}
+		resolved[action] = normalized
+	}
+
+	for _, context := range slices.Sorted(maps.Keys(KeyContexts)) {
+		bound := make(map[string]string)
+		for _, action := range KeyContexts[context] {
+			for _, key := range resolved[action] {
+				if other, ok := bound[key]; ok && other != action {
+					return nil, fmt.Errorf("[keys] %q is bound to both %s and %s", key, other, action)
+				}
+				bound[key] = action
+			}
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"ctrl-d", "ctrl+d"},
+		{"Ctrl+D", "ctrl+d"},
+		{"space", " "},
+		{"J", "J"},
+		{"alt-j", "alt+j"},
+		{"Escape", "esc"},
+		{"shift-tab", "shift+tab"},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeKey(tt.key)
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeKey(%q) = %q, %v; want %q", tt.key, got, err, tt.want)
+		}
+	}
+
+	for _, key := range []string{"ctrl-c", "ctrl-dd", "hyper-x", ""} {
+		if _, err := NormalizeKey(key); err == nil {
+			t.Errorf("NormalizeKey(%q): expected error", key)
+		}
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{"unknown key", "[keys]\nmark = \"ctrl-space\"\n", "unknown key"},
+		{"unknown action", "[keys]\nexplode = \"x\"\n", "unknown action"},
+		{"conflict", "[keys]\nmark = \"ctrl-d\"\n", "bound to both"},
+		{"conflict in branch picker", "[keys]\nfetch = \"ctrl-t\"\n", "bound to both"},
+		{"same key in different pickers", "[keys]\nfetch = \"ctrl-d\"\n", ""},
+		{"rebind and unbind", "[keys]\ndelete = []\nmark = [\"ctrl-d\", \"tab\"]\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadFrom(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				if !slices.Equal(cfg.Keys["delete"], KeyList{"ctrl+d"}) {
+					t.Errorf("invalid keys should fall back to defaults, got %v", cfg.Keys)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Unconfigured actions keep their defaults
+			if !slices.Equal(cfg.Keys["switch"], KeyList{"enter"}) {
+				t.Errorf("switch = %v, want default", cfg.Keys["switch"])
+			}
+		})
+	}
+
+	cfg, err := LoadFrom(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil || !slices.Equal(cfg.Keys["mark"], KeyList{"tab", " "}) {
+		t.Errorf("missing config: keys = %v, %v", cfg.Keys, err)
+	}
+}
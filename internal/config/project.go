@@ -30,11 +30,19 @@ type ProjectConfig struct {
 	// WorktreesDir overrides the global worktrees_dir for this project.
 	WorktreesDir string `toml:"worktrees_dir"`
 
+	// DefaultBase is the ref new branches are created from (e.g. "origin/main").
+	// Empty means the remote default branch from refs/remotes/origin/HEAD.
+	DefaultBase string `toml:"default_base"`
+
 	// Setup is a shell command (or list of commands) to run after creating a new worktree.
 	Setup StringOrSlice `toml:"setup"`
 
 	// OnEnter is a command to run after cd-ing into the worktree (e.g. "nvim", "code .").
 	OnEnter string `toml:"on_enter"`
+
+	// Env holds environment variables exported in the shell when switching
+	// to a worktree of this project.
+	Env map[string]string `toml:"env"`
 }
 
 // DefaultProjectConfig returns an empty ProjectConfig
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TemplateVars holds the values available to worktrees_dir templates
+type TemplateVars struct {
+	RepoName    string
+	RepoPath    string
+	Branch      string
+	User        string
+	Home        string
+	RemoteOwner string
+}
+
+// TemplateError describes a problem with a worktrees_dir template
+type TemplateError struct {
+	Template string
+	Reason   string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("invalid worktrees_dir template %q: %s", e.Template, e.Reason)
+}
+
+// placeholderNames lists every placeholder supported in templates
+var placeholderNames = []string{"repo_name", "branch", "branch_slug", "user", "home", "remote_owner"}
+
+var slugUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// BranchSlug turns a branch name into a single safe path element
+// (e.g. "feature/auth" becomes "feature-auth").
+func BranchSlug(branch string) string {
+	slug := slugUnsafe.ReplaceAllString(branch, "-")
+	slug = strings.Trim(slug, "-.")
+	if slug == "" {
+		return "branch"
+	}
+	return slug
+}
+
+// TemplateUses reports whether the template references the given placeholder
+func TemplateUses(tmpl, name string) bool {
+	return strings.Contains(tmpl, "{"+name+"}")
+}
+
+// ExpandTemplate substitutes placeholders in a worktrees_dir template and
+// returns the absolute path of the worktree for vars.Branch.
+//
+// A leading "~" expands to the home directory and relative results are
+// resolved against the repository path. If the template references neither
+// {branch} nor {branch_slug}, it names the parent directory and the branch
+// slug is appended as the final path element.
+func ExpandTemplate(tmpl string, vars TemplateVars) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		return "", &TemplateError{Template: tmpl, Reason: "template is empty"}
+	}
+
+	values := map[string]string{
+		"repo_name":    vars.RepoName,
+		"branch":       vars.Branch,
+		"branch_slug":  BranchSlug(vars.Branch),
+		"user":         vars.User,
+		"home":         vars.Home,
+		"remote_owner": vars.RemoteOwner,
+	}
+
+	var b strings.Builder
+	usesBranch := false
+	rest := tmpl
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			b.WriteString(rest)
+			break
+		}
+		if rest[open] == '}' {
+			return "", &TemplateError{Template: tmpl, Reason: "unmatched '}'"}
+		}
+		b.WriteString(rest[:open])
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return "", &TemplateError{Template: tmpl, Reason: "unclosed '{'"}
+		}
+		name := rest[open+1 : open+1+end]
+		value, ok := values[name]
+		if !ok {
+			return "", &TemplateError{
+				Template: tmpl,
+				Reason:   fmt.Sprintf("unknown placeholder {%s} (supported: {%s})", name, strings.Join(placeholderNames, "}, {")),
+			}
+		}
+		if value == "" {
+			return "", &TemplateError{Template: tmpl, Reason: fmt.Sprintf("placeholder {%s} has no value for this repository", name)}
+		}
+		if name == "branch" || name == "branch_slug" {
+			usesBranch = true
+		}
+		b.WriteString(value)
+		rest = rest[open+1+end+1:]
+	}
+
+	path := b.String()
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if vars.Home == "" {
+			return "", &TemplateError{Template: tmpl, Reason: "cannot expand ~ without a home directory"}
+		}
+		path = filepath.Join(vars.Home, strings.TrimPrefix(path, "~"))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(vars.RepoPath, path)
+	}
+	if !usesBranch {
+		path = filepath.Join(path, BranchSlug(vars.Branch))
+	}
+	return filepath.Clean(path), nil
+}
+
+// ResolveWorktreesTemplate picks the worktrees_dir template for a repository.
+// Precedence: project .wt.toml, then the global config, then the repo's
+// stored worktrees directory (repoDefault).
+func ResolveWorktreesTemplate(project ProjectConfig, global Config, repoDefault string) string {
+	if project.WorktreesDir != "" {
+		return project.WorktreesDir
+	}
+	if global.WorktreesDir != "" {
+		return global.WorktreesDir
+	}
+	return repoDefault
+}
+
+// CurrentUser returns the login name of the current user for {user}
+func CurrentUser() string {
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
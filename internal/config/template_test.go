@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	vars := TemplateVars{
+		RepoName:    "myapp",
+		RepoPath:    "/src/myapp",
+		Branch:      "feature/auth",
+		User:        "alice",
+		Home:        "/home/alice",
+		RemoteOwner: "acme",
+	}
+
+	tests := []struct {
+		name string
+		tmpl string
+		want string
+	}{
+		{"default sibling dir", "../{repo_name}.worktrees", "/src/myapp.worktrees/feature-auth"},
+		{"home with branch", "~/wt/{repo_name}/{branch}", "/home/alice/wt/myapp/feature/auth"},
+		{"branch slug", "{home}/wt/{remote_owner}/{repo_name}-{branch_slug}", "/home/alice/wt/acme/myapp-feature-auth"},
+		{"absolute dir", "/tmp/{user}", "/tmp/alice/feature-auth"},
+		{"relative inside repo", ".worktrees", "/src/myapp/.worktrees/feature-auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandTemplate(tt.tmpl, vars)
+			if err != nil {
+				t.Fatalf("ExpandTemplate(%q) failed: %v", tt.tmpl, err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpandTemplate(%q) = %q, want %q", tt.tmpl, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandTemplate_Errors(t *testing.T) {
+	vars := TemplateVars{RepoName: "myapp", RepoPath: "/src/myapp", Branch: "main"}
+
+	for _, tmpl := range []string{"", "{repo", "repo}", "{nope}", "{remote_owner}/x", "{a{b}"} {
+		_, err := ExpandTemplate(tmpl, vars)
+		var tmplErr *TemplateError
+		if !errors.As(err, &tmplErr) {
+			t.Errorf("ExpandTemplate(%q) error = %v, want *TemplateError", tmpl, err)
+		}
+	}
+}
+
+func TestResolveWorktreesTemplate(t *testing.T) {
+	project := ProjectConfig{WorktreesDir: "project"}
+	global := Config{WorktreesDir: "global"}
+
+	if got := ResolveWorktreesTemplate(project, global, "repo"); got != "project" {
+		t.Errorf("project config should win, got %q", got)
+	}
+	if got := ResolveWorktreesTemplate(ProjectConfig{}, global, "repo"); got != "global" {
+		t.Errorf("global config should win over repo default, got %q", got)
+	}
+	if got := ResolveWorktreesTemplate(ProjectConfig{}, Config{}, "repo"); got != "repo" {
+		t.Errorf("repo default should be the fallback, got %q", got)
+	}
+}
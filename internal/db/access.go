@@ -0,0 +1,100 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// accessLogRetention is how long access_log entries count towards frecency
+const accessLogRetention = "-90 days"
+
+// RecordAccess records that the user switched to a worktree
+func RecordAccess(db *sql.DB, worktreeID int64) error {
+	if _, err := db.Exec(`INSERT INTO access_log (worktree_id) VALUES (?)`, worktreeID); err != nil {
+		return err
+	}
+	// Old entries barely affect the score, so keep the log bounded
+	_, err := db.Exec(`DELETE FROM access_log WHERE accessed_at < datetime('now', ?)`, accessLogRetention)
+	return err
+}
+
+// SortMode controls the order in which worktrees are listed
+type SortMode string
+
+const (
+	SortName     SortMode = "name"     // current repo first, then by repo and branch name
+	SortFrecency SortMode = "frecency" // most frequently and recently used first
+	SortRecent   SortMode = "recent"   // most recently used first
+)
+
+// SortModes lists all sort modes in the order they are cycled through
+var SortModes = []SortMode{SortName, SortFrecency, SortRecent}
+
+// ParseSortMode parses a sort mode name. Empty means SortName.
+func ParseSortMode(s string) (SortMode, error) {
+	if s == "" {
+		return SortName, nil
+	}
+	for _, mode := range SortModes {
+		if string(mode) == s {
+			return mode, nil
+		}
+	}
+	names := make([]string, len(SortModes))
+	for i, mode := range SortModes {
+		names[i] = string(mode)
+	}
+	return "", fmt.Errorf("invalid sort mode %q (supported: %s)", s, strings.Join(names, ", "))
+}
+
+// Next returns the sort mode after m in SortModes, wrapping around
+func (m SortMode) Next() SortMode {
+	for i, mode := range SortModes {
+		if mode == m {
+			return SortModes[(i+1)%len(SortModes)]
+		}
+	}
+	return SortName
+}
+
+// SortWorktrees sorts worktrees in place by the given mode.
+// Ties are broken by name order, with currentRepoPath's worktrees first.
+func SortWorktrees(worktrees []*Worktree, mode SortMode, currentRepoPath string) {
+	byName := func(a, b *Worktree) bool {
+		aCurrent, bCurrent := a.RepoPath == currentRepoPath, b.RepoPath == currentRepoPath
+		if aCurrent != bCurrent {
+			return aCurrent
+		}
+		if a.RepoName != b.RepoName {
+			return a.RepoName < b.RepoName
+		}
+		if a.IsMain != b.IsMain {
+			return a.IsMain
+		}
+		return a.Branch < b.Branch
+	}
+
+	sort.SliceStable(worktrees, func(i, j int) bool {
+		a, b := worktrees[i], worktrees[j]
+		switch mode {
+		case SortFrecency:
+			if a.Frecency != b.Frecency {
+				return a.Frecency > b.Frecency
+			}
+		case SortRecent:
+			switch {
+			case a.LastAccessedAt != nil && b.LastAccessedAt != nil:
+				if !a.LastAccessedAt.Equal(*b.LastAccessedAt) {
+					return a.LastAccessedAt.After(*b.LastAccessedAt)
+				}
+			case a.LastAccessedAt != nil:
+				return true
+			case b.LastAccessedAt != nil:
+				return false
+			}
+		}
+		return byName(a, b)
+	})
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAccess_Frecency(t *testing.T) {
+	database, err := OpenAt(filepath.Join(t.TempDir(), "wt.db"))
+	if err != nil {
+		t.Fatalf("OpenAt failed: %v", err)
+	}
+	defer database.Close()
+
+	repo := &Repo{Path: "/src/app", Name: "app", WorktreesDir: "/src/app.worktrees"}
+	if err := UpsertRepo(database, repo); err != nil {
+		t.Fatalf("UpsertRepo failed: %v", err)
+	}
+	for _, branch := range []string{"a", "b"} {
+		wt := &Worktree{RepoID: repo.ID, Path: "/src/app.worktrees/" + branch, Branch: branch}
+		if err := UpsertWorktree(database, wt); err != nil {
+			t.Fatalf("UpsertWorktree failed: %v", err)
+		}
+		if branch == "b" {
+			for range 3 {
+				if err := RecordAccess(database, wt.ID); err != nil {
+					t.Fatalf("RecordAccess failed: %v", err)
+				}
+			}
+		}
+	}
+
+	worktrees, err := ListAllWorktrees(database)
+	if err != nil {
+		t.Fatalf("ListAllWorktrees failed: %v", err)
+	}
+	SortWorktrees(worktrees, SortFrecency, "")
+
+	if len(worktrees) != 2 || worktrees[0].Branch != "b" {
+		t.Fatalf("expected b first by frecency, got %+v", worktrees)
+	}
+	if worktrees[0].AccessCount != 3 || worktrees[0].Frecency != 12 {
+		t.Errorf("b: access count = %d, frecency = %v, want 3 and 12", worktrees[0].AccessCount, worktrees[0].Frecency)
+	}
+	if worktrees[0].LastAccessedAt == nil || time.Since(*worktrees[0].LastAccessedAt) > time.Minute {
+		t.Errorf("b: unexpected last access time %v", worktrees[0].LastAccessedAt)
+	}
+	if worktrees[1].AccessCount != 0 || worktrees[1].LastAccessedAt != nil {
+		t.Errorf("a: expected no accesses, got %+v", worktrees[1])
+	}
+}
+
+func TestSortWorktrees(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	worktrees := []*Worktree{
+		{RepoName: "zeta", RepoPath: "/zeta", Branch: "main", IsMain: true},
+		{RepoName: "alpha", RepoPath: "/alpha", Branch: "feature", LastAccessedAt: &earlier},
+		{RepoName: "alpha", RepoPath: "/alpha", Branch: "main", IsMain: true, LastAccessedAt: &now},
+	}
+
+	SortWorktrees(worktrees, SortName, "/zeta")
+	if got := branches(worktrees); got != "zeta/main alpha/main alpha/feature" {
+		t.Errorf("name sort = %s", got)
+	}
+
+	SortWorktrees(worktrees, SortRecent, "/zeta")
+	if got := branches(worktrees); got != "alpha/main alpha/feature zeta/main" {
+		t.Errorf("recent sort = %s", got)
+	}
+}
+
+func branches(worktrees []*Worktree) string {
+	var s string
+	for i, wt := range worktrees {
+		if i > 0 {
+			s += " "
+		}
+		s += wt.RepoName + "/" + wt.Branch
+	}
+	return s
+}
+
+func TestSortMode_Next(t *testing.T) {
+	if SortName.Next() != SortFrecency || SortFrecency.Next() != SortRecent || SortRecent.Next() != SortName {
+		t.Error("sort modes should cycle name -> frecency -> recent -> name")
+	}
+	if _, err := ParseSortMode("bogus"); err == nil {
+		t.Error("ParseSortMode should reject unknown modes")
+	}
+}
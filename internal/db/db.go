@@ -18,6 +18,14 @@ var migrationsFS embed.FS
 
 var defaultDB *sql.DB
 
+// DBTX is implemented by both *sql.DB and *sql.Tx, so write functions that
+// take it can be batched in a transaction
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
 // Open opens the database at the default location (~/.local/share/wt/wt.db)
 func Open() (*sql.DB, error) {
 	dbPath, err := DefaultPath()
@@ -35,7 +43,8 @@ func OpenAt(path string) (*sql.DB, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on")
+	// Writers wait for each other, e.g. a background scan and a sync
+	db, err := sql.Open("sqlite3", path+"?_foreign_keys=on&_busy_timeout=5000")
 	if err != nil {
 		return nil, err
 	}
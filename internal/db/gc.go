@@ -0,0 +1,58 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// PurgeDeleted permanently deletes worktrees and repositories that were
+// soft-deleted more than days ago, or all of them if days is 0. Worktrees
+// of a purged repository go with it. Returns the number of worktree and
+// repository rows deleted.
+func PurgeDeleted(db DBTX, days int) (worktrees, repos int64, err error) {
+	cutoff := fmt.Sprintf("-%d days", days)
+
+	res, err := db.Exec(`DELETE FROM worktrees WHERE deleted_at <= datetime('now', ?)`, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+	if worktrees, err = res.RowsAffected(); err != nil {
+		return 0, 0, err
+	}
+
+	res, err = db.Exec(`DELETE FROM repos WHERE deleted_at <= datetime('now', ?)`, cutoff)
+	if err != nil {
+		return 0, 0, err
+	}
+	if repos, err = res.RowsAffected(); err != nil {
+		return 0, 0, err
+	}
+	return worktrees, repos, nil
+}
+
+// ListPurgeableRepos returns the ids of the repositories PurgeDeleted would
+// delete with the same days
+func ListPurgeableRepos(db DBTX, days int) ([]int64, error) {
+	rows, err := db.Query(`SELECT id FROM repos WHERE deleted_at <= datetime('now', ?)`, fmt.Sprintf("-%d days", days))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Vacuum rebuilds the database file, returning the space freed by deleted
+// rows to the file system
+func Vacuum(db *sql.DB) error {
+	_, err := db.Exec(`VACUUM`)
+	return err
+}
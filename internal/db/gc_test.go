@@ -0,0 +1,63 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPurgeDeleted(t *testing.T) {
+	database, err := OpenAt(filepath.Join(t.TempDir(), "wt.db"))
+	if err != nil {
+		t.Fatalf("OpenAt failed: %v", err)
+	}
+	defer database.Close()
+
+	repo := &Repo{Path: "/src/app", Name: "app", WorktreesDir: "/src/app.worktrees"}
+	if err := UpsertRepo(database, repo); err != nil {
+		t.Fatalf("UpsertRepo failed: %v", err)
+	}
+	old := &Worktree{RepoID: repo.ID, Path: "/src/app.worktrees/old", Branch: "old"}
+	recent := &Worktree{RepoID: repo.ID, Path: "/src/app.worktrees/recent", Branch: "recent"}
+	for _, wt := range []*Worktree{old, recent} {
+		if err := UpsertWorktree(database, wt); err != nil {
+			t.Fatalf("UpsertWorktree failed: %v", err)
+		}
+		if err := SoftDeleteWorktree(database, wt.ID); err != nil {
+			t.Fatalf("SoftDeleteWorktree failed: %v", err)
+		}
+	}
+	if _, err := database.Exec(`UPDATE worktrees SET created_at = '2020-01-01 00:00:00', deleted_at = datetime('now', '-40 days') WHERE id = ?`, old.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec(`UPDATE worktrees SET created_at = '2020-01-01 00:00:00' WHERE id = ?`, recent.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	gone := &Repo{Path: "/src/gone", Name: "gone", WorktreesDir: "/src/gone.worktrees"}
+	if err := UpsertRepo(database, gone); err != nil {
+		t.Fatalf("UpsertRepo failed: %v", err)
+	}
+	if _, err := database.Exec(`UPDATE repos SET deleted_at = datetime('now', '-40 days') WHERE id = ?`, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	ids, err := ListPurgeableRepos(database, 30)
+	if err != nil || len(ids) != 1 || ids[0] != gone.ID {
+		t.Errorf("ListPurgeableRepos = %v, %v, want [%d]", ids, err, gone.ID)
+	}
+
+	worktrees, repos, err := PurgeDeleted(database, 30)
+	if err != nil {
+		t.Fatalf("PurgeDeleted failed: %v", err)
+	}
+	if worktrees != 1 || repos != 1 {
+		t.Errorf("purged %d worktrees and %d repos, want 1 and 1", worktrees, repos)
+	}
+
+	// Bringing back the recently deleted worktree starts it afresh
+	if err := UpsertWorktree(database, recent); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+	if recent.CreatedAt.Year() == 2020 {
+		t.Errorf("created_at of a resurrected worktree wasn't reset: %v", recent.CreatedAt)
+	}
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_access_log_accessed_at;
+DROP INDEX IF EXISTS idx_access_log_worktree_id;
+DROP TABLE IF EXISTS access_log;
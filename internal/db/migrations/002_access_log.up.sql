@@ -0,0 +1,8 @@
+CREATE TABLE access_log (
+    id INTEGER PRIMARY KEY,
+    worktree_id INTEGER NOT NULL REFERENCES worktrees(id) ON DELETE CASCADE,
+    accessed_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_access_log_worktree_id ON access_log(worktree_id);
+CREATE INDEX idx_access_log_accessed_at ON access_log(accessed_at);
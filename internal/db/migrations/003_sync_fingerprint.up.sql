@@ -0,0 +1 @@
+ALTER TABLE repos ADD COLUMN sync_fingerprint TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+CREATE TABLE scan_roots (
+    path TEXT PRIMARY KEY,
+    scanned_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
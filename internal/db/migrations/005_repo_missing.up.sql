@@ -0,0 +1 @@
+ALTER TABLE repos ADD COLUMN missing_at DATETIME;
@@ -0,0 +1,13 @@
+CREATE TABLE snapshots (
+    id INTEGER PRIMARY KEY,
+    repo_id INTEGER NOT NULL REFERENCES repos(id) ON DELETE CASCADE,
+    path TEXT NOT NULL,
+    branch TEXT NOT NULL,
+    head TEXT NOT NULL,
+    commit_sha TEXT NOT NULL,
+    stashes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    restored_at DATETIME
+);
+
+CREATE INDEX idx_snapshots_created_at ON snapshots(created_at);
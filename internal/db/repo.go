@@ -14,9 +14,17 @@ type Repo struct {
 	LastSyncedAt *time.Time
 	CreatedAt    time.Time
 	DeletedAt    *time.Time
+
+	// MissingAt is when a sync first found the repo's path gone from disk
+	MissingAt *time.Time
+
+	// SyncFingerprint identifies the worktree state at the last sync,
+	// so unchanged repos can be skipped
+	SyncFingerprint string
 }
 
-// UpsertRepo creates or updates a repository
+// UpsertRepo creates or updates a repository. A removed repository at the
+// same path is brought back as new.
 func UpsertRepo(db *sql.DB, repo *Repo) error {
 	query := `
 		INSERT INTO repos (path, name, worktrees_dir, last_synced_at)
@@ -25,7 +33,10 @@ func UpsertRepo(db *sql.DB, repo *Repo) error {
 			name = excluded.name,
 			worktrees_dir = excluded.worktrees_dir,
 			last_synced_at = excluded.last_synced_at,
-			deleted_at = NULL
+			sync_fingerprint = '',
+			created_at = CASE WHEN repos.deleted_at IS NULL THEN repos.created_at ELSE CURRENT_TIMESTAMP END,
+			deleted_at = NULL,
+			missing_at = NULL
 		RETURNING id, created_at
 	`
 	return db.QueryRow(query, repo.Path, repo.Name, repo.WorktreesDir, repo.LastSyncedAt).
@@ -35,14 +46,14 @@ func UpsertRepo(db *sql.DB, repo *Repo) error {
 // GetRepoByPath retrieves a repository by its path
 func GetRepoByPath(db *sql.DB, path string) (*Repo, error) {
 	query := `
-		SELECT id, path, name, worktrees_dir, last_synced_at, created_at, deleted_at
+		SELECT id, path, name, worktrees_dir, last_synced_at, created_at, deleted_at, sync_fingerprint, missing_at
 		FROM repos
 		WHERE path = ? AND deleted_at IS NULL
 	`
 	repo := &Repo{}
 	err := db.QueryRow(query, path).Scan(
 		&repo.ID, &repo.Path, &repo.Name, &repo.WorktreesDir,
-		&repo.LastSyncedAt, &repo.CreatedAt, &repo.DeletedAt,
+		&repo.LastSyncedAt, &repo.CreatedAt, &repo.DeletedAt, &repo.SyncFingerprint, &repo.MissingAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -56,14 +67,14 @@ func GetRepoByPath(db *sql.DB, path string) (*Repo, error) {
 // GetRepoByID retrieves a repository by its ID
 func GetRepoByID(db *sql.DB, id int64) (*Repo, error) {
 	query := `
-		SELECT id, path, name, worktrees_dir, last_synced_at, created_at, deleted_at
+		SELECT id, path, name, worktrees_dir, last_synced_at, created_at, deleted_at, sync_fingerprint, missing_at
 		FROM repos
 		WHERE id = ? AND deleted_at IS NULL
 	`
 	repo := &Repo{}
 	err := db.QueryRow(query, id).Scan(
 		&repo.ID, &repo.Path, &repo.Name, &repo.WorktreesDir,
-		&repo.LastSyncedAt, &repo.CreatedAt, &repo.DeletedAt,
+		&repo.LastSyncedAt, &repo.CreatedAt, &repo.DeletedAt, &repo.SyncFingerprint, &repo.MissingAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -77,7 +88,7 @@ func GetRepoByID(db *sql.DB, id int64) (*Repo, error) {
 // ListRepos retrieves all non-deleted repositories
 func ListRepos(db *sql.DB) ([]*Repo, error) {
 	query := `
-		SELECT id, path, name, worktrees_dir, last_synced_at, created_at, deleted_at
+		SELECT id, path, name, worktrees_dir, last_synced_at, created_at, deleted_at, sync_fingerprint, missing_at
 		FROM repos
 		WHERE deleted_at IS NULL
 		ORDER BY name
@@ -93,7 +104,7 @@ func ListRepos(db *sql.DB) ([]*Repo, error) {
 		repo := &Repo{}
 		err := rows.Scan(
 			&repo.ID, &repo.Path, &repo.Name, &repo.WorktreesDir,
-			&repo.LastSyncedAt, &repo.CreatedAt, &repo.DeletedAt,
+			&repo.LastSyncedAt, &repo.CreatedAt, &repo.DeletedAt, &repo.SyncFingerprint, &repo.MissingAt,
 		)
 		if err != nil {
 			return nil, err
@@ -103,6 +114,27 @@ func ListRepos(db *sql.DB) ([]*Repo, error) {
 	return repos, rows.Err()
 }
 
+// ListRepoPaths returns the paths of all repositories, including removed
+// ones, mapped to whether they were removed
+func ListRepoPaths(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query(`SELECT path, deleted_at IS NOT NULL FROM repos`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	paths := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		var removed bool
+		if err := rows.Scan(&path, &removed); err != nil {
+			return nil, err
+		}
+		paths[path] = removed
+	}
+	return paths, rows.Err()
+}
+
 // SoftDeleteRepo marks a repository as deleted
 func SoftDeleteRepo(db *sql.DB, id int64) error {
 	query := `UPDATE repos SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`
@@ -110,9 +142,75 @@ func SoftDeleteRepo(db *sql.DB, id int64) error {
 	return err
 }
 
+// DeleteRepo deletes a repository and its worktrees for good
+func DeleteRepo(db DBTX, id int64) error {
+	_, err := db.Exec(`DELETE FROM repos WHERE id = ?`, id)
+	return err
+}
+
+// SetRepoMissing records whether a repository's path is gone from disk.
+// The time it went missing is kept until it is found again.
+func SetRepoMissing(db DBTX, id int64, missing bool) error {
+	query := `UPDATE repos SET missing_at = NULL WHERE id = ?`
+	if missing {
+		query = `UPDATE repos SET missing_at = COALESCE(missing_at, CURRENT_TIMESTAMP) WHERE id = ?`
+	}
+	_, err := db.Exec(query, id)
+	return err
+}
+
+// RelocateRepo points a repository at a new path, keeping its id and
+// worktree history. A repo row already at the new path (tracked after the
+// move) is deleted with its worktrees. The fingerprint is reset so the next
+// sync lists the repo's worktrees again.
+func RelocateRepo(db DBTX, id int64, path, name, worktreesDir string) error {
+	// A repo already tracked at the new path is the same repository, so its
+	// snapshots, whose refs live there, move over before it is deleted
+	if _, err := db.Exec(`UPDATE snapshots SET repo_id = ? WHERE repo_id IN (SELECT id FROM repos WHERE path = ? AND id != ?)`, id, path, id); err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM repos WHERE path = ? AND id != ?`, path, id); err != nil {
+		return err
+	}
+	query := `
+		UPDATE repos
+		SET path = ?, name = ?, worktrees_dir = ?, sync_fingerprint = '', missing_at = NULL
+		WHERE id = ?
+	`
+	_, err := db.Exec(query, path, name, worktreesDir, id)
+	return err
+}
+
+// CountWorktrees returns the number of non-deleted worktrees per repository id
+func CountWorktrees(db *sql.DB) (map[int64]int, error) {
+	rows, err := db.Query(`SELECT repo_id, COUNT(*) FROM worktrees WHERE deleted_at IS NULL GROUP BY repo_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int)
+	for rows.Next() {
+		var repoID int64
+		var count int
+		if err := rows.Scan(&repoID, &count); err != nil {
+			return nil, err
+		}
+		counts[repoID] = count
+	}
+	return counts, rows.Err()
+}
+
 // UpdateLastSynced updates the last synced timestamp for a repository
-func UpdateLastSynced(db *sql.DB, id int64) error {
+func UpdateLastSynced(db DBTX, id int64) error {
 	query := `UPDATE repos SET last_synced_at = CURRENT_TIMESTAMP WHERE id = ?`
 	_, err := db.Exec(query, id)
 	return err
 }
+
+// UpdateSyncFingerprint records the worktree state a repository was synced at
+func UpdateSyncFingerprint(db DBTX, id int64, fingerprint string) error {
+	query := `UPDATE repos SET sync_fingerprint = ? WHERE id = ?`
+	_, err := db.Exec(query, fingerprint, id)
+	return err
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRelocateRepo_ReplacesRowAtNewPath(t *testing.T) {
+	database, err := OpenAt(filepath.Join(t.TempDir(), "wt.db"))
+	if err != nil {
+		t.Fatalf("OpenAt failed: %v", err)
+	}
+	defer database.Close()
+
+	repo := &Repo{Path: "/src/app", Name: "app", WorktreesDir: "/src/app.worktrees"}
+	if err := UpsertRepo(database, repo); err != nil {
+		t.Fatalf("UpsertRepo failed: %v", err)
+	}
+	if err := UpsertWorktree(database, &Worktree{RepoID: repo.ID, Path: "/src/app", Branch: "main", IsMain: true}); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+
+	// The repo was tracked again at its new location before relocating
+	moved := &Repo{Path: "/work/app", Name: "app", WorktreesDir: "/work/app.worktrees"}
+	if err := UpsertRepo(database, moved); err != nil {
+		t.Fatalf("UpsertRepo failed: %v", err)
+	}
+	if err := UpsertWorktree(database, &Worktree{RepoID: moved.ID, Path: "/work/app", Branch: "main", IsMain: true}); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+
+	snap := &Snapshot{RepoID: moved.ID, Path: "/work/app.worktrees/x", Branch: "x", Head: "abc", Commit: "abc"}
+	if err := CreateSnapshot(database, snap); err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+
+	if err := RelocateRepo(database, repo.ID, "/work/app", "app", "/work/app.worktrees"); err != nil {
+		t.Fatalf("RelocateRepo failed: %v", err)
+	}
+
+	got, err := GetRepoByPath(database, "/work/app")
+	if err != nil || got == nil {
+		t.Fatalf("GetRepoByPath = %v, %v", got, err)
+	}
+	if got.ID != repo.ID || got.WorktreesDir != "/work/app.worktrees" {
+		t.Errorf("got id %d dir %q, want id %d and /work/app.worktrees", got.ID, got.WorktreesDir, repo.ID)
+	}
+
+	// Snapshots of the replaced row belong to the relocated repo now
+	if got, err := GetSnapshot(database, snap.ID); err != nil || got == nil || got.RepoID != repo.ID {
+		t.Errorf("GetSnapshot = %+v, %v, want it moved to repo %d", got, err, repo.ID)
+	}
+
+	counts, err := CountWorktrees(database)
+	if err != nil {
+		t.Fatalf("CountWorktrees failed: %v", err)
+	}
+	if counts[repo.ID] != 1 || counts[moved.ID] != 0 {
+		t.Errorf("counts = %v, want 1 for the relocated repo only", counts)
+	}
+}
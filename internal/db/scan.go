@@ -0,0 +1,36 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ListScanTimes returns when each scan root was last scanned
+func ListScanTimes(db *sql.DB) (map[string]time.Time, error) {
+	rows, err := db.Query(`SELECT path, scanned_at FROM scan_roots`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make(map[string]time.Time)
+	for rows.Next() {
+		var path string
+		var scannedAt time.Time
+		if err := rows.Scan(&path, &scannedAt); err != nil {
+			return nil, err
+		}
+		times[path] = scannedAt
+	}
+	return times, rows.Err()
+}
+
+// RecordScan records that a scan root was just scanned
+func RecordScan(db DBTX, root string) error {
+	query := `
+		INSERT INTO scan_roots (path, scanned_at) VALUES (?, CURRENT_TIMESTAMP)
+		ON CONFLICT(path) DO UPDATE SET scanned_at = excluded.scanned_at
+	`
+	_, err := db.Exec(query, root)
+	return err
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot records the state of a worktree saved before it was removed.
+// The commits are kept alive by a ref in the repository (see Ref).
+type Snapshot struct {
+	ID         int64
+	RepoID     int64
+	Path       string
+	Branch     string
+	Head       string   // commit that was checked out
+	Commit     string   // Head plus uncommitted changes, Head if there were none
+	Stashes    []string // stash commits made on the branch
+	CreatedAt  time.Time
+	RestoredAt *time.Time
+
+	// Joined fields (not stored in DB)
+	RepoName string
+	RepoPath string
+}
+
+// Dirty reports whether the worktree had uncommitted changes
+func (s *Snapshot) Dirty() bool {
+	return s.Commit != s.Head
+}
+
+// Ref returns the ref that keeps the snapshot's commits from being
+// garbage collected by git
+func (s *Snapshot) Ref() string {
+	return "refs/wt/snapshots/" + strconv.FormatInt(s.ID, 10)
+}
+
+const snapshotSelect = `
+	SELECT s.id, s.repo_id, s.path, s.branch, s.head, s.commit_sha, s.stashes,
+	       s.created_at, s.restored_at, r.name, r.path
+	FROM snapshots s
+	JOIN repos r ON s.repo_id = r.id
+`
+
+func scanSnapshot(row rowScanner) (*Snapshot, error) {
+	s := &Snapshot{}
+	var stashes string
+	err := row.Scan(
+		&s.ID, &s.RepoID, &s.Path, &s.Branch, &s.Head, &s.Commit, &stashes,
+		&s.CreatedAt, &s.RestoredAt, &s.RepoName, &s.RepoPath,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.Stashes = strings.Fields(stashes)
+	return s, nil
+}
+
+// CreateSnapshot saves a snapshot, setting its ID and creation time
+func CreateSnapshot(db DBTX, s *Snapshot) error {
+	query := `
+		INSERT INTO snapshots (repo_id, path, branch, head, commit_sha, stashes)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id, created_at
+	`
+	return db.QueryRow(query, s.RepoID, s.Path, s.Branch, s.Head, s.Commit, strings.Join(s.Stashes, " ")).
+		Scan(&s.ID, &s.CreatedAt)
+}
+
+// GetSnapshot retrieves a snapshot by its ID
+func GetSnapshot(db *sql.DB, id int64) (*Snapshot, error) {
+	s, err := scanSnapshot(db.QueryRow(snapshotSelect+` WHERE s.id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// LatestSnapshot retrieves the most recent snapshot that hasn't been
+// restored yet
+func LatestSnapshot(db *sql.DB) (*Snapshot, error) {
+	query := snapshotSelect + `
+		WHERE s.restored_at IS NULL AND r.deleted_at IS NULL
+		ORDER BY s.id DESC LIMIT 1
+	`
+	s, err := scanSnapshot(db.QueryRow(query))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+// ListSnapshots retrieves all snapshots, newest first
+func ListSnapshots(db *sql.DB) ([]*Snapshot, error) {
+	rows, err := db.Query(snapshotSelect + ` ORDER BY s.id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []*Snapshot
+	for rows.Next() {
+		s, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// MarkSnapshotRestored records that a snapshot was restored
+func MarkSnapshotRestored(db DBTX, id int64) error {
+	_, err := db.Exec(`UPDATE snapshots SET restored_at = CURRENT_TIMESTAMP WHERE id = ?`, id)
+	return err
+}
+
+// DeleteSnapshot deletes a snapshot record
+func DeleteSnapshot(db DBTX, id int64) error {
+	_, err := db.Exec(`DELETE FROM snapshots WHERE id = ?`, id)
+	return err
+}
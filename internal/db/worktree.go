@@ -18,10 +18,74 @@ type Worktree struct {
 	// Joined fields (not stored in DB)
 	RepoName string
 	RepoPath string
+
+	// Access statistics aggregated from access_log
+	AccessCount    int
+	LastAccessedAt *time.Time
+	Frecency       float64
 }
 
-// UpsertWorktree creates or updates a worktree
-func UpsertWorktree(db *sql.DB, wt *Worktree) error {
+// worktreeSelect selects worktree rows joined with their repo and access
+// statistics, in the column order expected by scanWorktree.
+//
+// Frecency weighs each access by its age, like zoxide: accesses within the
+// last hour count 4, within a day 2, within a week 0.5 and older ones 0.25.
+const worktreeSelect = `
+	SELECT w.id, w.repo_id, w.path, w.branch, w.is_main, w.created_at, w.deleted_at,
+	       r.name, r.path,
+	       COALESCE(a.access_count, 0), a.last_accessed_at, COALESCE(a.frecency, 0)
+	FROM worktrees w
+	JOIN repos r ON w.repo_id = r.id
+	LEFT JOIN (
+		SELECT worktree_id,
+		       COUNT(*) AS access_count,
+		       CAST(strftime('%s', MAX(accessed_at)) AS INTEGER) AS last_accessed_at,
+		       SUM(CASE
+		           WHEN accessed_at >= datetime('now', '-1 hour') THEN 4.0
+		           WHEN accessed_at >= datetime('now', '-1 day') THEN 2.0
+		           WHEN accessed_at >= datetime('now', '-7 days') THEN 0.5
+		           ELSE 0.25
+		       END) AS frecency
+		FROM access_log
+		GROUP BY worktree_id
+	) a ON a.worktree_id = w.id
+`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWorktree(row rowScanner) (*Worktree, error) {
+	wt := &Worktree{}
+	var lastAccessed sql.NullInt64
+	err := row.Scan(
+		&wt.ID, &wt.RepoID, &wt.Path, &wt.Branch, &wt.IsMain,
+		&wt.CreatedAt, &wt.DeletedAt, &wt.RepoName, &wt.RepoPath,
+		&wt.AccessCount, &lastAccessed, &wt.Frecency,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if lastAccessed.Valid {
+		t := time.Unix(lastAccessed.Int64, 0)
+		wt.LastAccessedAt = &t
+	}
+	return wt, nil
+}
+
+// UpsertWorktree creates or updates a worktree. A deleted worktree at the
+// same path is brought back as new, without its access history.
+func UpsertWorktree(db DBTX, wt *Worktree) error {
+	_, err := db.Exec(`
+		DELETE FROM access_log WHERE worktree_id IN (
+			SELECT id FROM worktrees WHERE path = ? AND deleted_at IS NOT NULL
+		)
+	`, wt.Path)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO worktrees (repo_id, path, branch, is_main)
 		VALUES (?, ?, ?, ?)
@@ -29,6 +93,7 @@ func UpsertWorktree(db *sql.DB, wt *Worktree) error {
 			repo_id = excluded.repo_id,
 			branch = excluded.branch,
 			is_main = excluded.is_main,
+			created_at = CASE WHEN worktrees.deleted_at IS NULL THEN worktrees.created_at ELSE CURRENT_TIMESTAMP END,
 			deleted_at = NULL
 		RETURNING id, created_at
 	`
@@ -38,18 +103,10 @@ func UpsertWorktree(db *sql.DB, wt *Worktree) error {
 
 // GetWorktreeByPath retrieves a worktree by its path
 func GetWorktreeByPath(db *sql.DB, path string) (*Worktree, error) {
-	query := `
-		SELECT w.id, w.repo_id, w.path, w.branch, w.is_main, w.created_at, w.deleted_at,
-		       r.name, r.path
-		FROM worktrees w
-		JOIN repos r ON w.repo_id = r.id
+	query := worktreeSelect + `
 		WHERE w.path = ? AND w.deleted_at IS NULL
 	`
-	wt := &Worktree{}
-	err := db.QueryRow(query, path).Scan(
-		&wt.ID, &wt.RepoID, &wt.Path, &wt.Branch, &wt.IsMain,
-		&wt.CreatedAt, &wt.DeletedAt, &wt.RepoName, &wt.RepoPath,
-	)
+	wt, err := scanWorktree(db.QueryRow(query, path))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -61,11 +118,7 @@ func GetWorktreeByPath(db *sql.DB, path string) (*Worktree, error) {
 
 // ListWorktreesByRepo retrieves all non-deleted worktrees for a repository
 func ListWorktreesByRepo(db *sql.DB, repoID int64) ([]*Worktree, error) {
-	query := `
-		SELECT w.id, w.repo_id, w.path, w.branch, w.is_main, w.created_at, w.deleted_at,
-		       r.name, r.path
-		FROM worktrees w
-		JOIN repos r ON w.repo_id = r.id
+	query := worktreeSelect + `
 		WHERE w.repo_id = ? AND w.deleted_at IS NULL
 		ORDER BY w.is_main DESC, w.branch
 	`
@@ -74,11 +127,7 @@ func ListWorktreesByRepo(db *sql.DB, repoID int64) ([]*Worktree, error) {
 
 // ListAllWorktrees retrieves all non-deleted worktrees across all repos
 func ListAllWorktrees(db *sql.DB) ([]*Worktree, error) {
-	query := `
-		SELECT w.id, w.repo_id, w.path, w.branch, w.is_main, w.created_at, w.deleted_at,
-		       r.name, r.path
-		FROM worktrees w
-		JOIN repos r ON w.repo_id = r.id
+	query := worktreeSelect + `
 		WHERE w.deleted_at IS NULL AND r.deleted_at IS NULL
 		ORDER BY r.name, w.is_main DESC, w.branch
 	`
@@ -87,16 +136,12 @@ func ListAllWorktrees(db *sql.DB) ([]*Worktree, error) {
 
 // ListAllWorktreesWithRepoFirst retrieves all worktrees, with the specified repo's worktrees first
 func ListAllWorktreesWithRepoFirst(db *sql.DB, currentRepoPath string) ([]*Worktree, error) {
-	query := `
-		SELECT w.id, w.repo_id, w.path, w.branch, w.is_main, w.created_at, w.deleted_at,
-		       r.name, r.path
-		FROM worktrees w
-		JOIN repos r ON w.repo_id = r.id
+	query := worktreeSelect + `
 		WHERE w.deleted_at IS NULL AND r.deleted_at IS NULL
-		ORDER BY 
+		ORDER BY
 			CASE WHEN r.path = ? THEN 0 ELSE 1 END,
-			r.name, 
-			w.is_main DESC, 
+			r.name,
+			w.is_main DESC,
 			w.branch
 	`
 	return queryWorktrees(db, query, currentRepoPath)
@@ -111,11 +156,7 @@ func queryWorktrees(db *sql.DB, query string, args ...any) ([]*Worktree, error)
 
 	var worktrees []*Worktree
 	for rows.Next() {
-		wt := &Worktree{}
-		err := rows.Scan(
-			&wt.ID, &wt.RepoID, &wt.Path, &wt.Branch, &wt.IsMain,
-			&wt.CreatedAt, &wt.DeletedAt, &wt.RepoName, &wt.RepoPath,
-		)
+		wt, err := scanWorktree(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -124,6 +165,17 @@ func queryWorktrees(db *sql.DB, query string, args ...any) ([]*Worktree, error)
 	return worktrees, rows.Err()
 }
 
+// UpdateWorktree changes a worktree's branch and path in place, keeping its
+// id so access history follows it. A soft-deleted row left at the new path
+// is purged first.
+func UpdateWorktree(db DBTX, id int64, branch, path string) error {
+	if _, err := db.Exec(`DELETE FROM worktrees WHERE path = ? AND id != ? AND deleted_at IS NOT NULL`, path, id); err != nil {
+		return err
+	}
+	_, err := db.Exec(`UPDATE worktrees SET branch = ?, path = ? WHERE id = ?`, branch, path, id)
+	return err
+}
+
 // SoftDeleteWorktree marks a worktree as deleted
 func SoftDeleteWorktree(db *sql.DB, id int64) error {
 	query := `UPDATE worktrees SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`
@@ -139,7 +191,7 @@ func SoftDeleteWorktreeByPath(db *sql.DB, path string) error {
 }
 
 // SoftDeleteMissingWorktrees marks worktrees as deleted if they're not in the provided list of paths
-func SoftDeleteMissingWorktrees(db *sql.DB, repoID int64, existingPaths []string) error {
+func SoftDeleteMissingWorktrees(db DBTX, repoID int64, existingPaths []string) error {
 	if len(existingPaths) == 0 {
 		// Mark all worktrees for this repo as deleted
 		query := `UPDATE worktrees SET deleted_at = CURRENT_TIMESTAMP WHERE repo_id = ? AND deleted_at IS NULL`
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateWorktree_KeepsIDAndHistory(t *testing.T) {
+	database, err := OpenAt(filepath.Join(t.TempDir(), "wt.db"))
+	if err != nil {
+		t.Fatalf("OpenAt failed: %v", err)
+	}
+	defer database.Close()
+
+	repo := &Repo{Path: "/src/app", Name: "app", WorktreesDir: "/src/app.worktrees"}
+	if err := UpsertRepo(database, repo); err != nil {
+		t.Fatalf("UpsertRepo failed: %v", err)
+	}
+	wt := &Worktree{RepoID: repo.ID, Path: "/src/app.worktrees/fix-thing", Branch: "fix/thing"}
+	if err := UpsertWorktree(database, wt); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+	if err := RecordAccess(database, wt.ID); err != nil {
+		t.Fatalf("RecordAccess failed: %v", err)
+	}
+
+	// A soft-deleted worktree that used to live at the new path
+	old := &Worktree{RepoID: repo.ID, Path: "/src/app.worktrees/feature-thing", Branch: "feature/thing"}
+	if err := UpsertWorktree(database, old); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+	if err := SoftDeleteWorktree(database, old.ID); err != nil {
+		t.Fatalf("SoftDeleteWorktree failed: %v", err)
+	}
+
+	if err := UpdateWorktree(database, wt.ID, "feature/thing", old.Path); err != nil {
+		t.Fatalf("UpdateWorktree failed: %v", err)
+	}
+
+	got, err := GetWorktreeByPath(database, old.Path)
+	if err != nil || got == nil {
+		t.Fatalf("GetWorktreeByPath = %v, %v", got, err)
+	}
+	if got.ID != wt.ID || got.Branch != "feature/thing" || got.AccessCount != 1 {
+		t.Errorf("got id %d branch %q accesses %d, want id %d, feature/thing and 1",
+			got.ID, got.Branch, got.AccessCount, wt.ID)
+	}
+}
+
+func TestUpsertWorktree_ResurrectsWithoutHistory(t *testing.T) {
+	database, err := OpenAt(filepath.Join(t.TempDir(), "wt.db"))
+	if err != nil {
+		t.Fatalf("OpenAt failed: %v", err)
+	}
+	defer database.Close()
+
+	repo := &Repo{Path: "/src/app", Name: "app", WorktreesDir: "/src/app.worktrees"}
+	if err := UpsertRepo(database, repo); err != nil {
+		t.Fatalf("UpsertRepo failed: %v", err)
+	}
+	wt := &Worktree{RepoID: repo.ID, Path: "/src/app.worktrees/feature", Branch: "feature"}
+	if err := UpsertWorktree(database, wt); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+	if err := RecordAccess(database, wt.ID); err != nil {
+		t.Fatalf("RecordAccess failed: %v", err)
+	}
+
+	// Syncing a live worktree keeps its history
+	if err := UpsertWorktree(database, wt); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+	if got, _ := GetWorktreeByPath(database, wt.Path); got == nil || got.AccessCount != 1 {
+		t.Fatalf("got %+v, want 1 access kept", got)
+	}
+
+	if err := SoftDeleteWorktree(database, wt.ID); err != nil {
+		t.Fatalf("SoftDeleteWorktree failed: %v", err)
+	}
+	again := &Worktree{RepoID: repo.ID, Path: wt.Path, Branch: "other"}
+	if err := UpsertWorktree(database, again); err != nil {
+		t.Fatalf("UpsertWorktree failed: %v", err)
+	}
+	got, err := GetWorktreeByPath(database, wt.Path)
+	if err != nil || got == nil {
+		t.Fatalf("GetWorktreeByPath = %v, %v", got, err)
+	}
+	if got.AccessCount != 0 || got.LastAccessedAt != nil {
+		t.Errorf("resurrected worktree has %d accesses, want none", got.AccessCount)
+	}
+}
@@ -0,0 +1,269 @@
+package git
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBranchRef returns the ref that feature branches are merged into.
+// Prefers the remote default branch (e.g. "origin/main") from
+// refs/remotes/origin/HEAD, falling back to a local main or master branch.
+func DefaultBranchRef(repoPath string) (string, error) {
+	if output, err := run(repoPath, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if ref := strings.TrimSpace(output); ref != "" {
+			return ref, nil
+		}
+	}
+
+	for _, name := range []string{"main", "master"} {
+		if RefExists(repoPath, "refs/heads/"+name) {
+			return name, nil
+		}
+	}
+	return "", errors.New("cannot determine default branch (no origin/HEAD, main or master)")
+}
+
+// RefExists checks whether the given ref resolves to a commit
+func RefExists(repoPath, ref string) bool {
+	_, err := run(repoPath, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	return err == nil
+}
+
+// IsMerged reports whether branch is fully contained in base
+func IsMerged(repoPath, branch, base string) (bool, error) {
+	_, err := run(repoPath, "merge-base", "--is-ancestor", branch, base)
+	if err == nil {
+		return true, nil
+	}
+	var gitErr *Error
+	if errors.As(err, &gitErr) && gitErr.ExitCode == 1 {
+		return false, nil
+	}
+	return false, err
+}
+
+// HasOwnCommits reports whether a local branch has moved since it was
+// created, i.e. got commits of its own. A branch that was just created is an
+// ancestor of the branch it was created from, so without this it would look
+// merged. If the branch's reflog doesn't go back to its creation, it is
+// assumed to have commits.
+func HasOwnCommits(repoPath, branch string) bool {
+	output, err := run(repoPath, "log", "-g", "--format=%H %gs", "refs/heads/"+branch, "--")
+	if err != nil {
+		return true
+	}
+	lines := nonEmptyLines(output)
+	if len(lines) == 0 {
+		return true
+	}
+	// Newest first: the tip, then back to the creation
+	tip, _, _ := strings.Cut(lines[0], " ")
+	created, subject, _ := strings.Cut(lines[len(lines)-1], " ")
+	if !strings.HasPrefix(subject, "branch: Created from") {
+		return true
+	}
+	return tip != created
+}
+
+// IsSquashMerged reports whether the changes of branch were squash-merged
+// (or rebased) into base. It squashes the branch into a single temporary
+// commit on top of the merge base and asks `git cherry` whether base
+// already contains a commit with the same patch-id.
+func IsSquashMerged(repoPath, branch, base string) (bool, error) {
+	output, err := run(repoPath, "merge-base", base, branch)
+	if err != nil {
+		return false, err
+	}
+	mergeBase := strings.TrimSpace(output)
+
+	// commit-tree needs an identity, which may not be configured
+	output, err = runEnv(repoPath, []string{
+		"GIT_AUTHOR_NAME=wt", "GIT_AUTHOR_EMAIL=wt@localhost",
+		"GIT_COMMITTER_NAME=wt", "GIT_COMMITTER_EMAIL=wt@localhost",
+	}, "commit-tree", branch+"^{tree}", "-p", mergeBase, "-m", "wt squash check")
+	if err != nil {
+		return false, err
+	}
+	squashed := strings.TrimSpace(output)
+
+	output, err = run(repoPath, "cherry", base, squashed)
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(strings.TrimSpace(output), "-"), nil
+}
+
+// ListGoneBranches returns the local branches whose upstream branch
+// no longer exists on the remote
+func ListGoneBranches(repoPath string) (map[string]bool, error) {
+	output, err := run(repoPath, "for-each-ref", "--format=%(refname:short)%00%(upstream:track)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+
+	gone := make(map[string]bool)
+	for _, line := range nonEmptyLines(output) {
+		name, track, _ := strings.Cut(line, "\x00")
+		if track == "[gone]" {
+			gone[name] = true
+		}
+	}
+	return gone, nil
+}
+
+// LastCommitTime returns the committer date of the given ref
+func LastCommitTime(repoPath, ref string) (time.Time, error) {
+	output, err := run(repoPath, "log", "-1", "--format=%ct", ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// DeleteBranch deletes a local branch. Without force, git refuses to
+// delete branches that are not merged.
+func DeleteBranch(repoPath, branch string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	_, err := run(repoPath, "branch", flag, branch)
+	return err
+}
+
+// DeleteRemoteBranch deletes a branch on a remote
+func DeleteRemoteBranch(repoPath, remote, branch string) error {
+	_, err := run(repoPath, "push", "--quiet", remote, "--delete", branch)
+	return err
+}
+
+// UpstreamOf returns the remote and name of a local branch's upstream
+// branch. ok is false if it has none or the upstream is a local branch.
+func UpstreamOf(repoPath, branch string) (remote, name string, ok bool) {
+	return RemoteBranchOf(repoPath, branch+"@{upstream}")
+}
+
+// BranchesTracking returns the local branches whose upstream is the given
+// remote branch
+func BranchesTracking(repoPath, remote, name string) ([]string, error) {
+	output, err := run(repoPath, "for-each-ref", "--format=%(refname:short)%00%(upstream)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+
+	want := "refs/remotes/" + remote + "/" + name
+	var branches []string
+	for _, line := range nonEmptyLines(output) {
+		branch, upstream, _ := strings.Cut(line, "\x00")
+		if upstream == want {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// RenameBranch renames a local branch, including in the worktree that has
+// it checked out. Fails if newName already exists.
+func RenameBranch(repoPath, oldName, newName string) error {
+	_, err := run(repoPath, "branch", "-m", oldName, newName)
+	return err
+}
+
+// Branch is a local or remote-tracking branch
+type Branch struct {
+	Name         string // branch name without the remote, e.g. "feature/auth"
+	Remote       string // remote name for remote-tracking branches, empty for local ones
+	WorktreePath string // worktree the branch is checked out in, if any
+}
+
+// Ref returns the short ref name, e.g. "feature/auth" or "origin/feature/auth"
+func (b Branch) Ref() string {
+	if b.Remote == "" {
+		return b.Name
+	}
+	return b.Remote + "/" + b.Name
+}
+
+// ListBranches returns all local and remote-tracking branches.
+// Remote HEAD pointers (e.g. origin/HEAD) are skipped.
+func ListBranches(repoPath string) ([]Branch, error) {
+	remotes, err := ListRemotes(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := run(repoPath, "for-each-ref",
+		"--format=%(refname)%00%(symref)%00%(worktreepath)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+
+	var branches []Branch
+	for _, line := range nonEmptyLines(output) {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 3 || fields[1] != "" {
+			continue // symbolic ref like refs/remotes/origin/HEAD
+		}
+		ref, worktreePath := fields[0], fields[2]
+
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			branches = append(branches, Branch{Name: name, WorktreePath: worktreePath})
+			continue
+		}
+
+		remote, name := splitRemoteRef(remotes, strings.TrimPrefix(ref, "refs/remotes/"))
+		if remote == "" {
+			continue
+		}
+		branches = append(branches, Branch{Name: name, Remote: remote})
+	}
+	return branches, nil
+}
+
+// splitRemoteRef splits "origin/feature/x" into remote and branch name,
+// matching the longest remote name since remote names may contain slashes
+func splitRemoteRef(remotes []string, ref string) (remote, name string) {
+	for _, r := range remotes {
+		if strings.HasPrefix(ref, r+"/") && len(r) > len(remote) {
+			remote = r
+		}
+	}
+	if remote == "" {
+		return "", ""
+	}
+	return remote, strings.TrimPrefix(ref, remote+"/")
+}
+
+// RemoteBranchOf resolves ref and, if it is a remote-tracking branch,
+// returns its remote and branch name
+func RemoteBranchOf(repoPath, ref string) (remote, name string, ok bool) {
+	output, err := run(repoPath, "rev-parse", "--symbolic-full-name", ref)
+	if err != nil {
+		return "", "", false
+	}
+	rest, isRemote := strings.CutPrefix(strings.TrimSpace(output), "refs/remotes/")
+	if !isRemote {
+		return "", "", false
+	}
+	remotes, err := ListRemotes(repoPath)
+	if err != nil {
+		return "", "", false
+	}
+	remote, name = splitRemoteRef(remotes, rest)
+	return remote, name, remote != ""
+}
+
+// ListRemotes returns the names of the configured remotes
+func ListRemotes(repoPath string) ([]string, error) {
+	output, err := run(repoPath, "remote")
+	if err != nil {
+		return nil, err
+	}
+	return nonEmptyLines(output), nil
+}
@@ -0,0 +1,44 @@
+package git
+
+import (
+	"testing"
+
+	"github.com/roveo/wt/internal/gittest"
+)
+
+func TestDeleteRemoteBranch(t *testing.T) {
+	origin, repo := gittest.Clone(t)
+	gittest.Run(t, repo, "switch", "-q", "-c", "feature")
+	gittest.Run(t, repo, "push", "-q", "-u", "origin", "feature")
+
+	remote, name, ok := UpstreamOf(repo, "feature")
+	if !ok || remote != "origin" || name != "feature" {
+		t.Fatalf("UpstreamOf = %q, %q, %v, want origin, feature", remote, name, ok)
+	}
+	if err := DeleteRemoteBranch(repo, remote, name); err != nil {
+		t.Fatal(err)
+	}
+	if RefExists(origin, "refs/heads/feature") || RefExists(repo, "refs/remotes/origin/feature") {
+		t.Error("feature should be gone from the remote and its remote-tracking branch")
+	}
+}
+
+func TestHasOwnCommits(t *testing.T) {
+	repo := t.TempDir()
+	gittest.Repo(t, repo)
+	gittest.Run(t, repo, "branch", "fresh")
+	gittest.Run(t, repo, "switch", "-q", "-c", "work")
+	gittest.Commit(t, repo, "work")
+	gittest.Run(t, repo, "switch", "-q", "main")
+	gittest.Run(t, repo, "merge", "-q", "--ff-only", "work")
+
+	if HasOwnCommits(repo, "fresh") {
+		t.Error("a branch that was just created has no commits of its own")
+	}
+	if merged, err := IsMerged(repo, "fresh", "main"); err != nil || !merged {
+		t.Errorf("IsMerged(fresh) = %v, %v; it is contained in main", merged, err)
+	}
+	if !HasOwnCommits(repo, "work") {
+		t.Error("work has a commit of its own")
+	}
+}
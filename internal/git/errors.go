@@ -0,0 +1,146 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrorKind classifies common git failures so callers can react to them
+type ErrorKind int
+
+const (
+	ErrUnknown          ErrorKind = iota
+	ErrBranchCheckedOut           // branch is already checked out in another worktree
+	ErrDirtyWorktree              // worktree has modified or untracked files
+	ErrLocked                     // worktree is locked
+	ErrInvalidRef                 // branch, ref or revision does not exist or is invalid
+	ErrMissingRemote              // remote does not exist or cannot be reached
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrBranchCheckedOut:
+		return "branch already checked out"
+	case ErrDirtyWorktree:
+		return "dirty worktree"
+	case ErrLocked:
+		return "locked worktree"
+	case ErrInvalidRef:
+		return "invalid ref"
+	case ErrMissingRemote:
+		return "missing remote"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is a failed git command
+type Error struct {
+	Command  string   // executable, normally "git"
+	Args     []string // arguments passed to the command
+	Dir      string   // working directory
+	ExitCode int      // -1 if the command could not be started
+	Stderr   string   // trimmed stderr output
+	Kind     ErrorKind
+	Err      error // underlying error from os/exec
+}
+
+func (e *Error) Error() string {
+	if e.Stderr != "" {
+		return e.Stderr
+	}
+	return fmt.Sprintf("%s %s: %v", e.Command, strings.Join(e.Args, " "), e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// IsKind reports whether err is a git *Error of the given kind
+func IsKind(err error, kind ErrorKind) bool {
+	var gitErr *Error
+	return errors.As(err, &gitErr) && gitErr.Kind == kind
+}
+
+// errorPatterns maps stderr fragments to error kinds, checked in order
+var errorPatterns = []struct {
+	fragment string
+	kind     ErrorKind
+}{
+	{"is already checked out at", ErrBranchCheckedOut},
+	{"is already used by worktree at", ErrBranchCheckedOut},
+	{"contains modified or untracked files", ErrDirtyWorktree},
+	{"locked working tree", ErrLocked},
+	{"is locked", ErrLocked},
+	{"invalid reference", ErrInvalidRef},
+	{"not a valid object name", ErrInvalidRef},
+	{"not a valid branch name", ErrInvalidRef},
+	{"unknown revision", ErrInvalidRef},
+	{"bad revision", ErrInvalidRef},
+	{"needed a single revision", ErrInvalidRef},
+	{"' not found", ErrInvalidRef}, // git branch -d on a missing branch
+	{"no such remote", ErrMissingRemote},
+	{"does not appear to be a git repository", ErrMissingRemote},
+	{"could not read from remote repository", ErrMissingRemote},
+	{"could not resolve host", ErrMissingRemote},
+}
+
+// classify determines the kind of a git failure from its stderr
+func classify(stderr string) ErrorKind {
+	lower := strings.ToLower(stderr)
+	for _, p := range errorPatterns {
+		if strings.Contains(lower, p.fragment) {
+			return p.kind
+		}
+	}
+	return ErrUnknown
+}
+
+// run executes git with args in dir and returns its stdout.
+// Failures are returned as *Error.
+func run(dir string, args ...string) (string, error) {
+	return runEnv(dir, nil, args...)
+}
+
+// runEnv is like run, with extra environment variables
+func runEnv(dir string, env []string, args ...string) (string, error) {
+	return runContext(context.Background(), dir, env, args...)
+}
+
+// runContext is like runEnv, killing git when ctx is done
+func runContext(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Don't wait on a killed git stuck on I/O (e.g. a hung network mount)
+	cmd.WaitDelay = time.Second
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		gitErr := &Error{
+			Command:  "git",
+			Args:     args,
+			Dir:      dir,
+			ExitCode: -1,
+			Stderr:   strings.TrimSpace(stderr.String()),
+			Err:      err,
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			gitErr.ExitCode = exitErr.ExitCode()
+		}
+		gitErr.Kind = classify(gitErr.Stderr)
+		return stdout.String(), gitErr
+	}
+	return stdout.String(), nil
+}
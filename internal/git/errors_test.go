@@ -0,0 +1,48 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   ErrorKind
+	}{
+		{"fatal: 'feature' is already checked out at '/src/app.worktrees/feature'", ErrBranchCheckedOut},
+		{"fatal: 'feature' is already used by worktree at '/src/app.worktrees/feature'", ErrBranchCheckedOut},
+		{"fatal: '/src/app.worktrees/x' contains modified or untracked files, use --force to delete it", ErrDirtyWorktree},
+		{"fatal: cannot remove a locked working tree, lock reason: usb\nuse 'remove -f -f' to override or unlock first", ErrLocked},
+		{"fatal: invalid reference: origin/nope", ErrInvalidRef},
+		{"fatal: Not a valid object name: 'nope'", ErrInvalidRef},
+		{"error: branch 'nope' not found.", ErrInvalidRef},
+		{"fatal: 'upstream' does not appear to be a git repository", ErrMissingRemote},
+		{"error: No such remote 'upstream'", ErrMissingRemote},
+		{"fatal: something else went wrong", ErrUnknown},
+		{"", ErrUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := classify(tt.stderr); got != tt.want {
+			t.Errorf("classify(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestRunError(t *testing.T) {
+	_, err := run(t.TempDir(), "rev-parse", "--verify", "refs/heads/does-not-exist")
+	if err == nil {
+		t.Fatal("expected error outside a repository")
+	}
+	gitErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected *Error, got %T", err)
+	}
+	if gitErr.ExitCode == 0 || gitErr.Stderr == "" {
+		t.Errorf("expected exit code and stderr, got %d %q", gitErr.ExitCode, gitErr.Stderr)
+	}
+	if !IsKind(fmt.Errorf("wrapped: %w", err), gitErr.Kind) {
+		t.Error("IsKind should see through wrapping")
+	}
+}
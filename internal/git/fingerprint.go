@@ -0,0 +1,79 @@
+package git
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WorktreesFingerprint returns a hash of the state `git worktree list`
+// reports for the repo at repoPath: the main HEAD and, for every entry in
+// $GIT_COMMON_DIR/worktrees, its HEAD, gitdir and lock files. The files are
+// read directly, so this is much cheaper than running git.
+//
+// File contents are used rather than mtimes because the entry directories
+// are touched on every commit (index updates), which would defeat the cache.
+func WorktreesFingerprint(repoPath string) (string, error) {
+	commonDir, err := commonDirOf(repoPath)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	head, err := os.ReadFile(filepath.Join(commonDir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "HEAD %q\n", head)
+
+	// ReadDir sorts by name, so the hash doesn't depend on directory order
+	entries, err := os.ReadDir(filepath.Join(commonDir, "worktrees"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(commonDir, "worktrees", entry.Name())
+		fmt.Fprintf(h, "worktree %q\n", entry.Name())
+		for _, name := range []string{"HEAD", "gitdir", "locked"} {
+			// A missing file is part of the state (e.g. not locked)
+			data, _ := os.ReadFile(filepath.Join(dir, name))
+			fmt.Fprintf(h, "%s %q\n", name, data)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// commonDirOf returns the git directory of the main worktree at repoPath,
+// following a "gitdir:" file as used by submodules
+func commonDirOf(repoPath string) (string, error) {
+	gitPath := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return gitPath, nil
+	}
+
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return "", err
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("unrecognized .git file in %s", repoPath)
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoPath, dir)
+	}
+	return dir, nil
+}
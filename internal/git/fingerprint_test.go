@@ -0,0 +1,50 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/roveo/wt/internal/gittest"
+)
+
+func TestWorktreesFingerprint(t *testing.T) {
+	repo := t.TempDir()
+	gittest.Repo(t, repo)
+
+	fingerprint := func() string {
+		t.Helper()
+		fp, err := WorktreesFingerprint(repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fp
+	}
+
+	initial := fingerprint()
+	if initial != fingerprint() {
+		t.Fatal("fingerprint is not stable")
+	}
+
+	worktree := filepath.Join(t.TempDir(), "feature")
+	gittest.Run(t, repo, "worktree", "add", "-q", "-b", "feature", worktree)
+	added := fingerprint()
+	if added == initial {
+		t.Error("fingerprint unchanged after adding a worktree")
+	}
+
+	// Committing doesn't change the worktree list
+	gittest.Commit(t, worktree, "wip")
+	if fingerprint() != added {
+		t.Error("fingerprint changed after a commit")
+	}
+
+	gittest.Run(t, worktree, "switch", "-q", "-c", "other")
+	if fingerprint() == added {
+		t.Error("fingerprint unchanged after switching branches")
+	}
+
+	gittest.Run(t, repo, "worktree", "remove", worktree)
+	if fingerprint() != initial {
+		t.Error("fingerprint differs from initial after removing the worktree")
+	}
+}
@@ -0,0 +1,32 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roveo/wt/internal/gittest"
+)
+
+func TestCheckRemoval(t *testing.T) {
+	_, repo := gittest.Clone(t)
+	gittest.Commit(t, repo, "local only")
+	if err := os.WriteFile(filepath.Join(repo, "new.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gittest.Run(t, repo, "stash", "push", "-q", "-u", "-m", "parked")
+	if err := os.WriteFile(filepath.Join(repo, "other.txt"), []byte("y"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check, err := CheckRemoval(repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(check.Uncommitted) != 1 || len(check.Unpushed) != 1 || len(check.Stashes) != 1 {
+		t.Errorf("got %+v, want 1 uncommitted file, 1 unpushed commit and 1 stash", check)
+	}
+	if !check.Destructive() {
+		t.Error("uncommitted changes should make removal destructive")
+	}
+}
@@ -1,45 +1,38 @@
 package git
 
 import (
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
 // IsInsideRepo checks if the given path is inside a git repository
 func IsInsideRepo(path string) bool {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
-	cmd.Dir = path
-	output, err := cmd.Output()
+	output, err := run(path, "rev-parse", "--is-inside-work-tree")
 	if err != nil {
 		return false
 	}
-	return strings.TrimSpace(string(output)) == "true"
+	return strings.TrimSpace(output) == "true"
 }
 
 // GetRepoRoot returns the root directory of the current worktree
 // Note: When inside a worktree, this returns the worktree's root, not the main repo
 func GetRepoRoot(path string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	cmd.Dir = path
-	output, err := cmd.Output()
+	output, err := run(path, "rev-parse", "--show-toplevel")
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
 }
 
 // GetMainRepoPath returns the path to the main repository
 // This works correctly even when called from inside a worktree
 func GetMainRepoPath(path string) (string, error) {
 	// Get the common git dir (points to main repo's .git)
-	cmd := exec.Command("git", "rev-parse", "--git-common-dir")
-	cmd.Dir = path
-	output, err := cmd.Output()
+	output, err := run(path, "rev-parse", "--git-common-dir")
 	if err != nil {
 		return "", err
 	}
-	gitDir := strings.TrimSpace(string(output))
+	gitDir := strings.TrimSpace(output)
 
 	// The main repo path is the parent of .git directory
 	// Handle both absolute and relative paths
@@ -71,54 +64,36 @@ func GetDefaultWorktreesDir(repoPath string) string {
 
 // GetCurrentBranch returns the current branch name
 func GetCurrentBranch(path string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = path
-	output, err := cmd.Output()
+	output, err := run(path, "rev-parse", "--abbrev-ref", "HEAD")
 	if err != nil {
 		return "", err
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
 }
 
-// ListRemoteBranches returns a list of remote branches
-func ListRemoteBranches(path string) ([]string, error) {
-	cmd := exec.Command("git", "branch", "-r", "--format=%(refname:short)")
-	cmd.Dir = path
-	output, err := cmd.Output()
+// ListRemoteBranches returns the remote-tracking branches of all remotes
+func ListRemoteBranches(path string) ([]Branch, error) {
+	branches, err := ListBranches(path)
 	if err != nil {
 		return nil, err
 	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var branches []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		// Remove origin/ prefix if present
-		if strings.HasPrefix(line, "origin/") {
-			line = strings.TrimPrefix(line, "origin/")
+	var remote []Branch
+	for _, b := range branches {
+		if b.Remote != "" {
+			remote = append(remote, b)
 		}
-		// Skip HEAD pointer
-		if line == "HEAD" || strings.Contains(line, "->") {
-			continue
-		}
-		branches = append(branches, line)
 	}
-	return branches, nil
+	return remote, nil
 }
 
 // ListLocalBranches returns a list of local branches
 func ListLocalBranches(path string) ([]string, error) {
-	cmd := exec.Command("git", "branch", "--format=%(refname:short)")
-	cmd.Dir = path
-	output, err := cmd.Output()
+	output, err := run(path, "branch", "--format=%(refname:short)")
 	if err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	lines := strings.Split(strings.TrimSpace(output), "\n")
 	var branches []string
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
@@ -131,7 +106,46 @@ func ListLocalBranches(path string) ([]string, error) {
 
 // Fetch fetches from the remote
 func Fetch(path string) error {
-	cmd := exec.Command("git", "fetch", "--all", "--prune")
-	cmd.Dir = path
-	return cmd.Run()
+	_, err := run(path, "fetch", "--all", "--prune")
+	return err
+}
+
+// GetRemoteURL returns the URL of the given remote
+func GetRemoteURL(path, remote string) (string, error) {
+	output, err := run(path, "remote", "get-url", remote)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// ParseRemoteOwner extracts the owner (user or organization) from a remote URL.
+// Handles scp-style (git@host:owner/repo.git) and URL-style
+// (https://host/owner/repo) remotes. Returns "" if no owner can be found.
+func ParseRemoteOwner(url string) string {
+	url = strings.TrimSuffix(strings.TrimSpace(url), "/")
+	url = strings.TrimSuffix(url, ".git")
+
+	var repoPath string
+	if i := strings.Index(url, "://"); i >= 0 {
+		// URL-style: strip scheme and host
+		rest := url[i+3:]
+		slash := strings.Index(rest, "/")
+		if slash < 0 {
+			return ""
+		}
+		repoPath = rest[slash+1:]
+	} else if colon := strings.Index(url, ":"); colon >= 0 {
+		// scp-style: everything after the colon
+		repoPath = url[colon+1:]
+	} else {
+		return ""
+	}
+
+	parts := strings.Split(strings.Trim(repoPath, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	// Nested groups (e.g. GitLab subgroups) keep their full path
+	return strings.Join(parts[:len(parts)-1], "/")
 }
@@ -0,0 +1,130 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// FindRepos walks each root looking for git repositories at most depth
+// directory levels below it, and returns the paths of their main worktrees,
+// sorted and without duplicates. Linked worktrees found along the way
+// resolve to their main repository, which may lie outside the roots;
+// submodules are skipped. The walk doesn't descend into repositories,
+// symlinks or directories whose name or path matches one of the ignore
+// globs.
+//
+// Roots are walked concurrently. Roots that can't be read are reported in
+// the error, alongside the repos found in the others. If ctx is done, the
+// repos found so far are returned with ctx's error.
+func FindRepos(ctx context.Context, roots []string, depth int, ignore []string) ([]string, error) {
+	var (
+		mu    sync.Mutex
+		found []string
+		errs  []error
+		wg    sync.WaitGroup
+	)
+	for _, root := range roots {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := &repoWalker{ctx: ctx, depth: depth, ignore: ignore}
+			err := w.walk(root, 0)
+			mu.Lock()
+			defer mu.Unlock()
+			found = append(found, w.repos...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	slices.Sort(found)
+	return slices.Compact(found), errors.Join(errs...)
+}
+
+// repoWalker walks the directory tree of one scan root
+type repoWalker struct {
+	ctx    context.Context
+	depth  int
+	ignore []string
+	repos  []string
+}
+
+// walk looks for repositories in dir, which is level levels below the root.
+// Only a root that can't be read is an error; unreadable directories below
+// it are skipped.
+func (w *repoWalker) walk(dir string, level int) error {
+	if w.ctx.Err() != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if level == 0 {
+			return err
+		}
+		return nil
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == ".git" {
+			if repo, ok := mainRepoOf(dir, entry.IsDir()); ok {
+				w.repos = append(w.repos, repo)
+			}
+			return nil
+		}
+	}
+
+	if level >= w.depth {
+		return nil
+	}
+	for _, entry := range entries {
+		// Type bits come from ReadDir, so symlinks are not followed
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if w.ignored(entry.Name(), path) {
+			continue
+		}
+		w.walk(path, level+1)
+	}
+	return nil
+}
+
+// ignored reports whether a directory matches one of the ignore globs
+func (w *repoWalker) ignored(name, path string) bool {
+	for _, pattern := range w.ignore {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mainRepoOf returns the main worktree of the repository at dir. A .git
+// file marks a linked worktree or a submodule; only worktrees resolve to a
+// main repository with a .git directory.
+func mainRepoOf(dir string, gitDir bool) (string, bool) {
+	if gitDir {
+		return dir, true
+	}
+	main, err := GetMainRepoPath(dir)
+	if err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(filepath.Join(main, ".git")); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return main, true
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/roveo/wt/internal/gittest"
+)
+
+func TestFindRepos(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	gittest.Repo(t, filepath.Join(root, "app"))
+	gittest.Repo(t, filepath.Join(root, "work", "api"))
+	gittest.Repo(t, filepath.Join(root, "node_modules", "dep"))
+	gittest.Repo(t, filepath.Join(root, "a", "b", "c", "too-deep"))
+	// A linked worktree of a repo outside the root
+	gittest.Repo(t, filepath.Join(outside, "lib"))
+	gittest.Run(t, filepath.Join(outside, "lib"), "worktree", "add", "-q", "-b", "feature", filepath.Join(root, "lib-feature"))
+
+	got, err := FindRepos(context.Background(), []string{root}, 3, []string{"node_modules"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(outside, "lib"),
+		filepath.Join(root, "app"),
+		filepath.Join(root, "work", "api"),
+	}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("FindRepos = %v, want %v", got, want)
+	}
+
+	if _, err := FindRepos(context.Background(), []string{filepath.Join(root, "missing")}, 3, nil); err == nil {
+		t.Error("expected an error for a missing root")
+	}
+}
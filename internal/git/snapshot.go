@@ -0,0 +1,169 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// snapshotEnv sets the identity of snapshot commits, so they can be made
+// without a configured user
+var snapshotEnv = []string{
+	"GIT_AUTHOR_NAME=wt", "GIT_AUTHOR_EMAIL=wt@localhost",
+	"GIT_COMMITTER_NAME=wt", "GIT_COMMITTER_EMAIL=wt@localhost",
+}
+
+// Snapshot is the state of a worktree captured before it is removed
+type Snapshot struct {
+	Head    string   // commit checked out, empty on a branch without commits
+	Commit  string   // Head plus all uncommitted changes, Head if there are none
+	Stashes []string // stash commits made on the worktree's branch
+}
+
+// Dirty reports whether the worktree had uncommitted changes
+func (s *Snapshot) Dirty() bool {
+	return s.Commit != s.Head
+}
+
+// TakeSnapshot captures the worktree at path without changing it: the
+// checked out commit, a commit holding the uncommitted changes (staged,
+// unstaged and untracked files, but not ignored ones) and the stashes made
+// on branch. The changes are committed through a copy of the index, so the
+// worktree's own index is left alone. The commits are unreferenced until
+// kept with UpdateRef.
+func TakeSnapshot(path, branch string) (*Snapshot, error) {
+	snap := &Snapshot{}
+	if out, err := run(path, "rev-parse", "--verify", "-q", "HEAD"); err == nil {
+		snap.Head = strings.TrimSpace(out)
+	}
+
+	tree, err := worktreeTree(path)
+	if err != nil {
+		return nil, err
+	}
+
+	snap.Commit = snap.Head
+	if snap.Head == "" || !sameTree(path, snap.Head, tree) {
+		args := []string{"commit-tree", tree, "-m", "wt snapshot of " + path}
+		if snap.Head != "" {
+			args = append(args, "-p", snap.Head)
+		}
+		out, err := runEnv(path, snapshotEnv, args...)
+		if err != nil {
+			return nil, err
+		}
+		snap.Commit = strings.TrimSpace(out)
+	}
+
+	if snap.Stashes, err = listStashes(path, branch); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// worktreeTree writes a tree of all files in the worktree at path, as
+// `git add -A` would stage them, using a temporary copy of its index
+func worktreeTree(path string) (string, error) {
+	out, err := run(path, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+	index := strings.TrimSpace(out)
+
+	tmp, err := os.MkdirTemp("", "wt-snapshot-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	// A copy of the index lets git skip files whose stat info is unchanged.
+	// Without one (no commits yet) git starts from an empty index.
+	tmpIndex := filepath.Join(tmp, "index")
+	if data, err := os.ReadFile(index); err == nil {
+		if err := os.WriteFile(tmpIndex, data, 0600); err != nil {
+			return "", err
+		}
+	}
+
+	env := []string{"GIT_INDEX_FILE=" + tmpIndex}
+	if _, err := runEnv(path, env, "add", "-A"); err != nil {
+		return "", err
+	}
+	out, err = runEnv(path, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// sameTree reports whether commit's tree is tree
+func sameTree(path, commit, tree string) bool {
+	out, err := run(path, "rev-parse", commit+"^{tree}")
+	return err == nil && strings.TrimSpace(out) == tree
+}
+
+// SnapshotPatch returns the uncommitted changes of a snapshot as a binary
+// patch that `git apply` can reapply on top of its Head
+func SnapshotPatch(repoPath string, snap *Snapshot) (string, error) {
+	return run(repoPath, "show", "--format=", "--binary", "--no-color", "--no-ext-diff",
+		"--src-prefix=a/", "--dst-prefix=b/", snap.Commit)
+}
+
+// ApplyPatch applies a patch file to the worktree at path, without staging
+// it. Nothing is changed if any part of the patch doesn't apply.
+func ApplyPatch(path, patchFile string) error {
+	_, err := run(path, "apply", "--whitespace=nowarn", patchFile)
+	return err
+}
+
+// UpdateRef points ref at commit, creating it if needed. Commits reachable
+// from a ref are kept by git gc.
+func UpdateRef(repoPath, ref, commit string) error {
+	_, err := run(repoPath, "update-ref", ref, commit)
+	return err
+}
+
+// DeleteRef deletes ref
+func DeleteRef(repoPath, ref string) error {
+	_, err := run(repoPath, "update-ref", "-d", ref)
+	return err
+}
+
+// ResolveRef returns the commit ref points at
+func ResolveRef(repoPath, ref string) (string, error) {
+	out, err := run(repoPath, "rev-parse", "--verify", "-q", ref+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// RestoreStashes adds the given stash commits back to the stash list,
+// skipping ones that are still in it. Returns how many were added.
+func RestoreStashes(repoPath string, commits []string) (int, error) {
+	out, err := run(repoPath, "stash", "list", "--format=%H")
+	if err != nil {
+		return 0, err
+	}
+	present := make(map[string]bool)
+	for _, line := range nonEmptyLines(out) {
+		present[line] = true
+	}
+
+	restored := 0
+	for _, commit := range commits {
+		if present[commit] {
+			continue
+		}
+		subject, err := run(repoPath, "log", "-1", "--format=%s", commit)
+		if err != nil {
+			return restored, fmt.Errorf("stash %s: %w", commit, err)
+		}
+		if _, err := run(repoPath, "stash", "store", "-m", strings.TrimSpace(subject), commit); err != nil {
+			return restored, err
+		}
+		restored++
+	}
+	return restored, nil
+}
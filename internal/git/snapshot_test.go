@@ -0,0 +1,66 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roveo/wt/internal/gittest"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	repo := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gittest.Run(t, repo, "init", "-q", "-b", "main")
+	write(filepath.Join(repo, "tracked.txt"), "one\n")
+	gittest.Run(t, repo, "add", "tracked.txt")
+	gittest.Run(t, repo, "commit", "-q", "-m", "init")
+
+	clean, err := TakeSnapshot(repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clean.Dirty() || clean.Head == "" {
+		t.Fatalf("clean worktree: got %+v", clean)
+	}
+
+	write(filepath.Join(repo, "tracked.txt"), "two\n")
+	write(filepath.Join(repo, "untracked.txt"), "new\n")
+	statusBefore := gittest.Run(t, repo, "status", "--porcelain")
+
+	snap, err := TakeSnapshot(repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.Dirty() {
+		t.Fatal("dirty worktree: snapshot has no changes")
+	}
+	if status := gittest.Run(t, repo, "status", "--porcelain"); status != statusBefore {
+		t.Errorf("snapshot changed the worktree status:\n%s\nwant:\n%s", status, statusBefore)
+	}
+
+	// Reapplying the patch on a fresh checkout brings the changes back
+	patch, err := SnapshotPatch(repo, snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patchFile := filepath.Join(t.TempDir(), "changes.patch")
+	write(patchFile, patch)
+	restored := filepath.Join(t.TempDir(), "restored")
+	if err := AddDetachedWorktree(repo, snap.Head, restored); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyPatch(restored, patchFile); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"tracked.txt": "two\n", "untracked.txt": "new\n"} {
+		if got, _ := os.ReadFile(filepath.Join(restored, name)); string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package git
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorktreeStatus summarizes the working tree and branch state of a worktree
+type WorktreeStatus struct {
+	Staged    int `json:"staged"`    // files with staged changes
+	Modified  int `json:"modified"`  // files with unstaged changes
+	Untracked int `json:"untracked"` // untracked files
+	Conflicts int `json:"conflicts"` // files with merge conflicts
+
+	Upstream string `json:"upstream"` // upstream branch, empty if none
+	Ahead    int    `json:"ahead"`    // commits not on upstream
+	Behind   int    `json:"behind"`   // upstream commits not on this branch
+
+	Stashes int `json:"stashes"` // stash entries created on this worktree's branch
+}
+
+// IsDirty returns true if the worktree has any uncommitted changes
+func (s *WorktreeStatus) IsDirty() bool {
+	return s.Staged > 0 || s.Modified > 0 || s.Untracked > 0 || s.Conflicts > 0
+}
+
+// GetWorktreeStatus returns the status of the worktree at the given path
+func GetWorktreeStatus(path string) (*WorktreeStatus, error) {
+	output, err := run(path, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return nil, err
+	}
+
+	status, branch := parseStatus(output)
+
+	// Stashes are shared by all worktrees, so only count the ones made on this branch
+	if branch != "" {
+		count, err := countStashes(path, branch)
+		if err == nil {
+			status.Stashes = count
+		}
+	}
+
+	return status, nil
+}
+
+// parseStatus parses `git status --porcelain=v2 --branch` output.
+// Returns the status and the current branch name ("" if detached).
+func parseStatus(output string) (*WorktreeStatus, string) {
+	status := &WorktreeStatus{}
+	var branch string
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			branch = strings.TrimPrefix(line, "# branch.head ")
+			if branch == "(detached)" {
+				branch = ""
+			}
+
+		case strings.HasPrefix(line, "# branch.upstream "):
+			status.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+
+		case strings.HasPrefix(line, "# branch.ab "):
+			// Format: "# branch.ab +<ahead> -<behind>"
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "):
+			// Changed or renamed entry: "1 XY ..." where X is staged, Y is unstaged
+			if len(line) < 4 {
+				continue
+			}
+			if line[2] != '.' {
+				status.Staged++
+			}
+			if line[3] != '.' {
+				status.Modified++
+			}
+
+		case strings.HasPrefix(line, "u "):
+			status.Conflicts++
+
+		case strings.HasPrefix(line, "? "):
+			status.Untracked++
+		}
+	}
+
+	return status, branch
+}
+
+// countStashes counts stash entries created on the given branch
+func countStashes(path, branch string) (int, error) {
+	stashes, err := listStashes(path, branch)
+	return len(stashes), err
+}
+
+// listStashes returns the commits of stash entries created on the given branch
+func listStashes(path, branch string) ([]string, error) {
+	output, err := run(path, "stash", "list", "--format=%H %gs")
+	if err != nil {
+		return nil, err
+	}
+
+	var stashes []string
+	for _, line := range nonEmptyLines(output) {
+		commit, subject, _ := strings.Cut(line, " ")
+		if stashedOn(subject, branch) {
+			stashes = append(stashes, commit)
+		}
+	}
+	return stashes, nil
+}
+
+// stashedOn reports whether a stash subject, like "WIP on <branch>: ..." or
+// "On <branch>: ...", is of a stash made on branch
+func stashedOn(subject, branch string) bool {
+	return strings.HasPrefix(subject, "WIP on "+branch+":") || strings.HasPrefix(subject, "On "+branch+":")
+}
+
+// WorktreeDetails holds the information shown in the picker's preview pane
+type WorktreeDetails struct {
+	Path           string
+	Upstream       string    // upstream branch, empty if none
+	LastCommitDate time.Time // zero if the branch has no commits
+	Log            []string  // recent commits, one line each
+	Status         []string  // `git status --short` lines
+}
+
+// GetWorktreeDetails collects preview details for the worktree at the given path,
+// including up to logLimit recent commits
+func GetWorktreeDetails(path string, logLimit int) (*WorktreeDetails, error) {
+	details := &WorktreeDetails{Path: path}
+
+	output, err := run(path, "status", "--short")
+	if err != nil {
+		return nil, err
+	}
+	details.Status = nonEmptyLines(output)
+
+	if output, err := run(path, "log", "--oneline", "-n", strconv.Itoa(logLimit)); err == nil {
+		details.Log = nonEmptyLines(output)
+	}
+
+	if output, err := run(path, "log", "-1", "--format=%cI"); err == nil {
+		details.LastCommitDate, _ = time.Parse(time.RFC3339, strings.TrimSpace(output))
+	}
+
+	// Fails when there is no upstream, which just leaves it empty
+	if output, err := run(path, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		details.Upstream = strings.TrimSpace(output)
+	}
+
+	return details, nil
+}
+
+// RemovalCheck lists what is at stake when removing a worktree
+type RemovalCheck struct {
+	Uncommitted []string // `git status --short` lines, lost with the worktree
+	Unpushed    []string // commits not on any remote, one line each
+	Stashes     []string // stash entries made on the worktree's branch
+}
+
+// Destructive reports whether removing the worktree loses work
+func (c *RemovalCheck) Destructive() bool {
+	return len(c.Uncommitted) > 0
+}
+
+// CheckRemoval collects the uncommitted files, unpushed commits and stashes
+// of the worktree at path. Commits are only checked in repos with remotes,
+// as otherwise every commit would count.
+func CheckRemoval(path, branch string) (*RemovalCheck, error) {
+	check := &RemovalCheck{}
+
+	output, err := run(path, "status", "--short", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	check.Uncommitted = nonEmptyLines(output)
+
+	if remotes, err := ListRemotes(path); err == nil && len(remotes) > 0 {
+		// Fails on a branch without commits, which has nothing to push
+		if output, err := run(path, "log", "--oneline", "--no-decorate", "HEAD", "--not", "--remotes"); err == nil {
+			check.Unpushed = nonEmptyLines(output)
+		}
+	}
+
+	output, err = run(path, "stash", "list", "--format=%gd: %gs")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range nonEmptyLines(output) {
+		_, subject, _ := strings.Cut(line, ": ")
+		if stashedOn(subject, branch) {
+			check.Stashes = append(check.Stashes, line)
+		}
+	}
+	return check, nil
+}
+
+// nonEmptyLines splits output into lines, dropping empty ones
+func nonEmptyLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,49 @@
+package git
+
+import "testing"
+
+func TestParseStatus(t *testing.T) {
+	output := `# branch.oid 1234567890abcdef
+# branch.head feature/auth
+# branch.upstream origin/feature/auth
+# branch.ab +2 -1
+1 M. N... 100644 100644 100644 abc abc staged.go
+1 .M N... 100644 100644 100644 abc abc modified.go
+1 MM N... 100644 100644 100644 abc abc both.go
+2 R. N... 100644 100644 100644 abc abc R100 new.go	old.go
+u UU N... 100644 100644 100644 100644 abc abc abc conflict.go
+? untracked.txt
+? other.txt
+`
+	status, branch := parseStatus(output)
+
+	if branch != "feature/auth" {
+		t.Errorf("branch = %q, want %q", branch, "feature/auth")
+	}
+	want := WorktreeStatus{
+		Staged:    3,
+		Modified:  2,
+		Untracked: 2,
+		Conflicts: 1,
+		Upstream:  "origin/feature/auth",
+		Ahead:     2,
+		Behind:    1,
+	}
+	if *status != want {
+		t.Errorf("status = %+v, want %+v", *status, want)
+	}
+	if !status.IsDirty() {
+		t.Error("status should be dirty")
+	}
+}
+
+func TestParseStatus_CleanDetached(t *testing.T) {
+	status, branch := parseStatus("# branch.oid 1234567890abcdef\n# branch.head (detached)\n")
+
+	if branch != "" {
+		t.Errorf("branch = %q, want empty for detached HEAD", branch)
+	}
+	if status.IsDirty() || status.Upstream != "" {
+		t.Errorf("status = %+v, want clean with no upstream", *status)
+	}
+}
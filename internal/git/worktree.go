@@ -2,8 +2,7 @@ package git
 
 import (
 	"bufio"
-	"fmt"
-	"os/exec"
+	"context"
 	"strings"
 )
 
@@ -16,14 +15,17 @@ type WorktreeInfo struct {
 
 // ListWorktrees returns all worktrees for the repository at the given path
 func ListWorktrees(repoPath string) ([]WorktreeInfo, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
+	return ListWorktreesContext(context.Background(), repoPath)
+}
+
+// ListWorktreesContext is like ListWorktrees, giving up when ctx is done
+func ListWorktreesContext(ctx context.Context, repoPath string) ([]WorktreeInfo, error) {
+	output, err := runContext(ctx, repoPath, nil, "worktree", "list", "--porcelain")
 	if err != nil {
 		return nil, err
 	}
 
-	return parseWorktreeList(string(output))
+	return parseWorktreeList(output)
 }
 
 func parseWorktreeList(output string) ([]WorktreeInfo, error) {
@@ -80,49 +82,67 @@ func parseWorktreeList(output string) ([]WorktreeInfo, error) {
 	return worktrees, scanner.Err()
 }
 
-// AddWorktree creates a new worktree for the given branch
-func AddWorktree(repoPath, branch, targetPath string) error {
-	// First, try to create from an existing branch
-	cmd := exec.Command("git", "worktree", "add", targetPath, branch)
-	cmd.Dir = repoPath
-	if _, err := cmd.CombinedOutput(); err != nil {
-		// If that fails, try creating a new branch from the remote
-		cmd = exec.Command("git", "worktree", "add", "-b", branch, targetPath, "origin/"+branch)
-		cmd.Dir = repoPath
-		if _, err := cmd.CombinedOutput(); err != nil {
-			// Last resort: create new branch from current HEAD
-			cmd = exec.Command("git", "worktree", "add", "-b", branch, targetPath)
-			cmd.Dir = repoPath
-			if output, err := cmd.CombinedOutput(); err != nil {
-				// Return the last error with git's message
-				errMsg := strings.TrimSpace(string(output))
-				if errMsg != "" {
-					return fmt.Errorf("%s", errMsg)
-				}
-				return err
-			}
+// AddWorktree creates a new worktree for the given branch.
+// If the branch exists locally it is checked out as-is. Otherwise a new
+// branch is created from base (HEAD of the main repo if empty). When base is
+// the remote-tracking branch of the same name (e.g. origin/feature for
+// feature), the new branch tracks it; other bases are not tracked, so the
+// branch doesn't end up with e.g. origin/main as its upstream.
+func AddWorktree(repoPath, branch, base, targetPath string) error {
+	var args []string
+	switch {
+	case RefExists(repoPath, "refs/heads/"+branch):
+		args = []string{"worktree", "add", targetPath, branch}
+	case base == "":
+		args = []string{"worktree", "add", "-b", branch, targetPath}
+	default:
+		track := "--no-track"
+		if remote, name, ok := RemoteBranchOf(repoPath, base); ok && name == branch && remote != "" {
+			track = "--track"
 		}
+		args = []string{"worktree", "add", track, "-b", branch, targetPath, base}
 	}
-	return nil
+
+	_, err := run(repoPath, args...)
+	return err
+}
+
+// AddDetachedWorktree creates a new worktree with commit checked out and
+// no branch
+func AddDetachedWorktree(repoPath, commit, targetPath string) error {
+	_, err := run(repoPath, "worktree", "add", "--detach", targetPath, commit)
+	return err
 }
 
 // RemoveWorktree removes a worktree
 func RemoveWorktree(repoPath, worktreePath string) error {
-	cmd := exec.Command("git", "worktree", "remove", worktreePath)
-	cmd.Dir = repoPath
-	return cmd.Run()
+	_, err := run(repoPath, "worktree", "remove", worktreePath)
+	return err
 }
 
 // RemoveWorktreeForce forcefully removes a worktree
 func RemoveWorktreeForce(repoPath, worktreePath string) error {
-	cmd := exec.Command("git", "worktree", "remove", "--force", worktreePath)
-	cmd.Dir = repoPath
-	return cmd.Run()
+	_, err := run(repoPath, "worktree", "remove", "--force", worktreePath)
+	return err
+}
+
+// MoveWorktree moves a linked worktree to a new path. The new path's parent
+// directory must exist.
+func MoveWorktree(repoPath, worktreePath, newPath string) error {
+	_, err := run(repoPath, "worktree", "move", worktreePath, newPath)
+	return err
+}
+
+// RepairWorktrees fixes the links between the main repository and its
+// worktrees after either was moved. worktreePaths are the new locations of
+// moved worktrees.
+func RepairWorktrees(repoPath string, worktreePaths ...string) error {
+	_, err := run(repoPath, append([]string{"worktree", "repair"}, worktreePaths...)...)
+	return err
 }
 
 // PruneWorktrees removes stale worktree entries
 func PruneWorktrees(repoPath string) error {
-	cmd := exec.Command("git", "worktree", "prune")
-	cmd.Dir = repoPath
-	return cmd.Run()
+	_, err := run(repoPath, "worktree", "prune")
+	return err
 }
@@ -0,0 +1,56 @@
+// Package gittest creates git repositories for tests
+package gittest
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// Run runs git in dir with a fixed identity, so commits and stashes work
+// without a configured user, failing the test on error. Returns the output.
+func Run(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			t.Fatalf("git %v: %v\n%s", args, err, exitErr.Stderr)
+		}
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return string(out)
+}
+
+// Commit makes an empty commit in the worktree at dir
+func Commit(t testing.TB, dir, msg string) {
+	t.Helper()
+	Run(t, dir, "commit", "-q", "--allow-empty", "-m", msg)
+}
+
+// Repo creates dir and a repository in it with one commit on main
+func Repo(t testing.TB, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	Run(t, dir, "init", "-q", "-b", "main")
+	Commit(t, dir, "init")
+}
+
+// Clone creates a bare origin repository and a clone of it with one commit
+// pushed to main
+func Clone(t testing.TB) (origin, repo string) {
+	t.Helper()
+	origin = t.TempDir()
+	repo = filepath.Join(t.TempDir(), "repo")
+	Run(t, origin, "init", "-q", "--bare", "-b", "main")
+	Run(t, origin, "clone", "-q", origin, repo)
+	Commit(t, repo, "init")
+	Run(t, repo, "push", "-q", "origin", "main")
+	return origin, repo
+}
@@ -0,0 +1,33 @@
+// Package proc finds running processes that use a directory
+package proc
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Process is a running process
+type Process struct {
+	PID     int
+	Command string
+}
+
+func (p Process) String() string {
+	return fmt.Sprintf("%s (pid %d)", p.Command, p.PID)
+}
+
+// Describe lists processes for a message, like "bash (pid 12), vim (pid 34)"
+func Describe(procs []Process) string {
+	names := make([]string, len(procs))
+	for i, p := range procs {
+		names[i] = p.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// isInside reports whether path is dir or inside it
+func isInside(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
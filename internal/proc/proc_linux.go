@@ -0,0 +1,41 @@
+//go:build linux
+
+package proc
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// InDir returns the processes whose working directory is dir or inside it,
+// other than the current one. Processes of other users, whose /proc
+// entries can't be read, are not reported.
+func InDir(dir string) ([]Process, error) {
+	// /proc shows resolved paths
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	self := os.Getpid()
+	var procs []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		cwd, err := os.Readlink(filepath.Join("/proc", entry.Name(), "cwd"))
+		if err != nil || !isInside(cwd, dir) {
+			continue
+		}
+		comm, _ := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+		procs = append(procs, Process{PID: pid, Command: strings.TrimSpace(string(comm))})
+	}
+	return procs, nil
+}
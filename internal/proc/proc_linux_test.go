@@ -0,0 +1,38 @@
+//go:build linux
+
+package proc
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestInDir(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("sleep", "10")
+	cmd.Dir = sub
+	if err := cmd.Start(); err != nil {
+		t.Skipf("can't start sleep: %v", err)
+	}
+	defer cmd.Process.Kill()
+
+	procs, err := InDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(procs) != 1 || procs[0].PID != cmd.Process.Pid || procs[0].Command != "sleep" {
+		t.Errorf("InDir = %v, want sleep (pid %d)", procs, cmd.Process.Pid)
+	}
+
+	// A sibling directory with the same prefix doesn't count
+	if procs, _ := InDir(dir + "-other"); len(procs) != 0 {
+		t.Errorf("InDir of a sibling = %v, want none", procs)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package proc
+
+// InDir returns the processes whose working directory is dir or inside it.
+// Only Linux is supported; elsewhere no processes are reported.
+func InDir(dir string) ([]Process, error) {
+	return nil, nil
+}
@@ -0,0 +1,221 @@
+package shell
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DirectiveFileEnv is set by the shell wrapper to a temp file that wt
+	// writes directives to; the wrapper sources it after wt exits
+	DirectiveFileEnv = "WT_DIRECTIVE_FILE"
+
+	// ShellEnv is set by the shell wrapper to the shell directives are
+	// written for (bash, zsh, fish, nu, pwsh, elvish or xonsh)
+	ShellEnv = "WT_SHELL"
+)
+
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// syntax describes how directives are written for a shell. The formats
+// take the quoted argument (setenv: the name, then the quoted value).
+type syntax struct {
+	quote  func(string) string
+	chdir  string
+	setenv string
+	run    string // empty to pass commands through verbatim
+	attach string
+}
+
+var syntaxes = map[string]syntax{
+	"sh": {
+		quote:  quotePOSIX,
+		chdir:  "cd -- %s",
+		setenv: "export %s=%s",
+		attach: "tmux attach -t %s",
+	},
+	"fish": {
+		quote:  quoteFish,
+		chdir:  "cd %s",
+		setenv: "set -gx %s %s",
+		attach: "tmux attach -t %s",
+	},
+	// Nushell can't source a file chosen at runtime, so its wrapper
+	// reads one JSON object per line and applies them itself
+	"nu": {
+		quote:  quoteJSON,
+		chdir:  `{"type":"chdir","value":%s}`,
+		setenv: `{"type":"setenv","name":"%s","value":%s}`,
+		run:    `{"type":"run","value":%s}`,
+		attach: `{"type":"attach","value":%s}`,
+	},
+	"pwsh": {
+		quote:  quotePowerShell,
+		chdir:  "Set-Location -LiteralPath %s",
+		setenv: "$env:%s = %s",
+		attach: "tmux attach -t %s",
+	},
+	"elvish": {
+		quote:  quoteElvish,
+		chdir:  "cd %s",
+		setenv: "set-env %s %s",
+		attach: "tmux attach -t %s",
+	},
+	"xonsh": {
+		quote:  quotePython,
+		chdir:  "cd @(%s)",
+		setenv: "$%s = %s",
+		attach: "tmux attach -t @(%s)",
+	},
+}
+
+// Directives collects commands for the calling shell to run after wt exits:
+// changing directory, setting env vars, running hooks and attaching to tmux.
+// Arguments are quoted for the target shell, so paths and values can
+// contain any characters.
+type Directives struct {
+	syntax syntax
+	lines  []string
+}
+
+// NewDirectives returns directives for the shell named by $WT_SHELL.
+// Shells without their own syntax get POSIX sh syntax.
+func NewDirectives() *Directives {
+	return NewDirectivesFor(os.Getenv(ShellEnv))
+}
+
+// NewDirectivesFor returns directives for the given shell
+func NewDirectivesFor(shell string) *Directives {
+	s, ok := syntaxes[shell]
+	if !ok {
+		s = syntaxes["sh"]
+	}
+	return &Directives{syntax: s}
+}
+
+// Chdir changes the shell's working directory
+func (d *Directives) Chdir(path string) {
+	d.add(fmt.Sprintf(d.syntax.chdir, d.syntax.quote(path)))
+}
+
+// Setenv exports an environment variable in the shell
+func (d *Directives) Setenv(name, value string) error {
+	if !envNameRe.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name: %q", name)
+	}
+	d.add(fmt.Sprintf(d.syntax.setenv, name, d.syntax.quote(value)))
+	return nil
+}
+
+// Run runs a command line in the shell. The command is user-provided shell
+// code (e.g. on_enter) and is passed through unquoted where the shell
+// can evaluate it directly.
+func (d *Directives) Run(command string) {
+	command = strings.TrimSpace(command)
+	if command == "" {
+		return
+	}
+	if d.syntax.run != "" {
+		command = fmt.Sprintf(d.syntax.run, d.syntax.quote(command))
+	}
+	d.add(command)
+}
+
+// Attach attaches the terminal to a tmux session
+func (d *Directives) Attach(session string) {
+	d.add(fmt.Sprintf(d.syntax.attach, d.syntax.quote(session)))
+}
+
+// Empty reports whether no directives were added
+func (d *Directives) Empty() bool {
+	return len(d.lines) == 0
+}
+
+// String returns the directives as a script for the target shell
+func (d *Directives) String() string {
+	if d.Empty() {
+		return ""
+	}
+	return strings.Join(d.lines, "\n") + "\n"
+}
+
+// Flush writes the directives to $WT_DIRECTIVE_FILE and clears them.
+// Without the file (an older wrapper, or wt run directly) they are printed
+// to stdout, which older wrappers eval.
+func (d *Directives) Flush() error {
+	if d.Empty() {
+		return nil
+	}
+	script := d.String()
+	d.lines = nil
+
+	path := os.Getenv(DirectiveFileEnv)
+	if path == "" {
+		_, err := fmt.Print(script)
+		return err
+	}
+
+	// The wrapper creates the file with mktemp; never create one ourselves
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open directive file: %w", err)
+	}
+	if _, err := f.WriteString(script); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write directive file: %w", err)
+	}
+	return f.Close()
+}
+
+func (d *Directives) add(line string) {
+	d.lines = append(d.lines, line)
+}
+
+// quotePOSIX quotes s for sh, bash and zsh
+func quotePOSIX(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish quotes s for fish, where only \ and ' are special inside single quotes
+func quoteFish(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// quotePowerShell quotes s for PowerShell. Inside single quotes a quote is
+// escaped by doubling it, and PowerShell also treats typographic single
+// quotes as quotes.
+func quotePowerShell(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '\u2018', '\u2019', '\u201a', '\u201b':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+// quoteElvish quotes s for elvish, where ' is doubled inside single quotes
+func quoteElvish(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// quotePython returns s as a Python string literal for xonsh
+func quotePython(s string) string {
+	// Go's escapes (\n, \t, \xhh, \uhhhh, \Uhhhhhhhh) are valid in Python
+	return strconv.Quote(s)
+}
+
+// quoteJSON returns s as a JSON string for nushell
+func quoteJSON(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
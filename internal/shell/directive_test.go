@@ -0,0 +1,114 @@
+package shell
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectivesQuoting(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"bash", `cd -- '/tmp/it'\''s $HOME'` + "\n" + `export NAME='a\b'` + "\n"},
+		{"", `cd -- '/tmp/it'\''s $HOME'` + "\n" + `export NAME='a\b'` + "\n"},
+		{"fish", `cd '/tmp/it\'s $HOME'` + "\n" + `set -gx NAME 'a\\b'` + "\n"},
+		{"pwsh", `Set-Location -LiteralPath '/tmp/it''s $HOME'` + "\n" + `$env:NAME = 'a\b'` + "\n"},
+		{"elvish", `cd '/tmp/it''s $HOME'` + "\n" + `set-env NAME 'a\b'` + "\n"},
+		{"xonsh", `cd @("/tmp/it's $HOME")` + "\n" + `$NAME = "a\\b"` + "\n"},
+		{"nu", `{"type":"chdir","value":"/tmp/it's $HOME"}` + "\n" + `{"type":"setenv","name":"NAME","value":"a\\b"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		d := NewDirectivesFor(tt.shell)
+		d.Chdir("/tmp/it's $HOME")
+		if err := d.Setenv("NAME", `a\b`); err != nil {
+			t.Fatal(err)
+		}
+		if got := d.String(); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.shell, got, tt.want)
+		}
+	}
+
+	if err := NewDirectivesFor("bash").Setenv("BAD-NAME", "x"); err == nil {
+		t.Error("expected error for invalid env var name")
+	}
+}
+
+// wrapperShells describes how to run a script in each shell and the
+// commands that print the working directory and $WT_TEST_VALUE after wt
+var wrapperShells = []struct {
+	name  string
+	args  []string // flags before the script
+	after string
+	value bool // wt's output is the wrapper's value, printed after it ran
+}{
+	{"bash", []string{"-c"}, "wt\npwd\necho \"$WT_TEST_VALUE\"\n", false},
+	{"zsh", []string{"-f", "-c"}, "wt\npwd\necho \"$WT_TEST_VALUE\"\n", false},
+	{"fish", []string{"--no-config", "-c"}, "wt\npwd\necho \"$WT_TEST_VALUE\"\n", false},
+	{"nu", []string{"--no-config-file", "-c"}, "let out = (wt | str trim)\nprint $out\nprint $env.PWD\nprint $env.WT_TEST_VALUE\n", true},
+	{"pwsh", []string{"-NoProfile", "-NonInteractive", "-Command"}, "wt\n(Get-Location).Path\n$env:WT_TEST_VALUE\n", false},
+	{"elvish", []string{"-norc", "-c"}, "wt\npwd\necho $E:WT_TEST_VALUE\n", false},
+	{"xonsh", []string{"--no-rc", "-c"}, "wt\nprint($PWD)\nprint($WT_TEST_VALUE)\n", false},
+}
+
+// TestWrappers runs each shell's wrapper against a fake wt that writes
+// directives, checking that they are applied. Shells that aren't installed
+// are skipped.
+func TestWrappers(t *testing.T) {
+	for _, sh := range wrapperShells {
+		t.Run(sh.name, func(t *testing.T) {
+			shellPath, err := exec.LookPath(sh.name)
+			if err != nil {
+				t.Skipf("%s not installed", sh.name)
+			}
+
+			target := filepath.Join(t.TempDir(), `it's a "dir" $x`)
+			if err := os.Mkdir(target, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			d := NewDirectivesFor(sh.name)
+			d.Chdir(target)
+			if err := d.Setenv("WT_TEST_VALUE", `quote's "and" $dollar`); err != nil {
+				t.Fatal(err)
+			}
+			d.Run("echo entered")
+
+			// The fake wt writes the directives and prints to stdout,
+			// which must be passed through untouched
+			bin := t.TempDir()
+			directives := filepath.Join(bin, "directives")
+			if err := os.WriteFile(directives, []byte(d.String()), 0644); err != nil {
+				t.Fatal(err)
+			}
+			fake := "#!/bin/sh\necho normal output\ncat " + directives + " >> \"$" + DirectiveFileEnv + "\"\n"
+			if err := os.WriteFile(filepath.Join(bin, "wt"), []byte(fake), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			init, err := GetInit(sh.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cmd := exec.Command(shellPath, append(sh.args, init+"\n"+sh.after)...)
+			cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+			output, err := cmd.CombinedOutput()
+			if err != nil {
+				t.Fatalf("%v: %s", err, output)
+			}
+
+			want := []string{"normal output", "entered", target, `quote's "and" $dollar`}
+			if sh.value {
+				want[0], want[1] = want[1], want[0]
+			}
+			got := strings.Split(strings.TrimSpace(string(output)), "\n")
+			if strings.Join(got, "\n") != strings.Join(want, "\n") {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
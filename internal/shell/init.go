@@ -6,20 +6,16 @@ import "fmt"
 func BashInit() string {
 	return `# wt shell integration for bash
 wt() {
-    local result exit_code first_line
-    result="$(command wt "$@")"
+    local directive_file exit_code
+    directive_file="$(mktemp -t wt.XXXXXX)" || return 1
+    WT_DIRECTIVE_FILE="$directive_file" WT_SHELL=bash command wt "$@"
     exit_code=$?
-    if [[ $exit_code -ne 0 ]]; then
-        [[ -n "$result" ]] && echo "$result" >&2
-        return $exit_code
-    fi
-    # Check if first line is a cd or tmux attach command
-    first_line="${result%%$'\n'*}"
-    if [[ "$first_line" == cd\ * || "$first_line" == "tmux attach"* ]]; then
-        eval "$result"
-    elif [[ -n "$result" ]]; then
-        echo "$result"
+    # Apply directives (cd, env vars, on_enter, tmux attach) written by wt
+    if [[ $exit_code -eq 0 && -s "$directive_file" ]]; then
+        source "$directive_file"
     fi
+    rm -f "$directive_file"
+    return $exit_code
 }
 `
 }
@@ -28,20 +24,16 @@ wt() {
 func ZshInit() string {
 	return `# wt shell integration for zsh
 wt() {
-    local result exit_code first_line
-    result="$(command wt "$@")"
+    local directive_file exit_code
+    directive_file="$(mktemp -t wt.XXXXXX)" || return 1
+    WT_DIRECTIVE_FILE="$directive_file" WT_SHELL=zsh command wt "$@"
     exit_code=$?
-    if [[ $exit_code -ne 0 ]]; then
-        [[ -n "$result" ]] && echo "$result" >&2
-        return $exit_code
-    fi
-    # Check if first line is a cd or tmux attach command
-    first_line="${result%%$'\n'*}"
-    if [[ "$first_line" == cd\ * || "$first_line" == "tmux attach"* ]]; then
-        eval "$result"
-    elif [[ -n "$result" ]]; then
-        echo "$result"
+    # Apply directives (cd, env vars, on_enter, tmux attach) written by wt
+    if [[ $exit_code -eq 0 && -s "$directive_file" ]]; then
+        source "$directive_file"
     fi
+    rm -f "$directive_file"
+    return $exit_code
 }
 `
 }
@@ -50,23 +42,125 @@ wt() {
 func FishInit() string {
 	return `# wt shell integration for fish
 function wt
-    set -l result (command wt $argv)
+    set -l directive_file (mktemp -t wt.XXXXXX); or return 1
+    WT_DIRECTIVE_FILE=$directive_file WT_SHELL=fish command wt $argv
     set -l exit_code $status
-    if test $exit_code -ne 0
-        echo $result >&2
-        return $exit_code
-    end
-    # Check if first line is a cd or tmux attach command
-    set -l first_line $result[1]
-    if string match -q 'cd *' "$first_line"; or string match -q 'tmux attach*' "$first_line"
-        eval (string join "; " $result)
-    else if test -n "$result"
-        echo $result
+    # Apply directives (cd, env vars, on_enter, tmux attach) written by wt
+    if test $exit_code -eq 0; and test -s $directive_file
+        source $directive_file
     end
+    rm -f $directive_file
+    return $exit_code
 end
 `
 }
 
+// NushellInit returns the nushell initialization script.
+// Nushell can't source a file chosen at runtime, so wt writes JSON
+// directives that the wrapper applies itself. wt's output is returned as
+// the function's value, so it can be piped and assigned like the binary's.
+func NushellInit() string {
+	return `# wt shell integration for nushell
+def --env --wrapped wt [...args] {
+    let directive_file = (^mktemp -t wt.XXXXXX | str trim)
+    $env.WT_DIRECTIVE_FILE = $directive_file
+    $env.WT_SHELL = "nu"
+    # Only stdout is captured; the picker draws on stderr
+    let output = try { ^wt ...$args | collect } catch { null }
+    let exit_code = $env.LAST_EXIT_CODE
+    hide-env WT_DIRECTIVE_FILE WT_SHELL
+    # Apply directives (cd, env vars, on_enter, tmux attach) written by wt
+    let directives = if $exit_code == 0 {
+        open --raw $directive_file | lines | where {|line| $line != "" } | each {|line| $line | from json }
+    } else { [] }
+    rm -f $directive_file
+    if $exit_code != 0 {
+        error make --unspanned {msg: $"wt exited with code ($exit_code)"}
+    }
+    cd ($directives | where type == "chdir" | get value | prepend $env.PWD | last)
+    load-env ($directives | where type == "setenv" | reduce --fold {} {|it, acc| $acc | merge {($it.name): $it.value} })
+    for directive in ($directives | where type in ["run", "attach"]) {
+        if $directive.type == "run" {
+            ^$nu.current-exe -c $directive.value
+        } else {
+            ^tmux attach -t $directive.value
+        }
+    }
+    $output
+}
+`
+}
+
+// PowerShellInit returns the PowerShell (pwsh) initialization script
+func PowerShellInit() string {
+	return `# wt shell integration for PowerShell
+function wt {
+    $wtCommand = Get-Command -Name wt -CommandType Application -ErrorAction Stop | Select-Object -First 1
+    $directiveFile = [System.IO.Path]::GetTempFileName()
+    $env:WT_DIRECTIVE_FILE = $directiveFile
+    $env:WT_SHELL = 'pwsh'
+    try {
+        & $wtCommand @args
+        $exitCode = $LASTEXITCODE
+    } finally {
+        Remove-Item Env:WT_DIRECTIVE_FILE, Env:WT_SHELL -ErrorAction SilentlyContinue
+    }
+    try {
+        # Apply directives (cd, env vars, on_enter, tmux attach) written by wt
+        if ($exitCode -eq 0 -and (Get-Item -LiteralPath $directiveFile).Length -gt 0) {
+            . ([ScriptBlock]::Create((Get-Content -Raw -LiteralPath $directiveFile)))
+        }
+    } finally {
+        Remove-Item -LiteralPath $directiveFile -Force -ErrorAction SilentlyContinue
+    }
+    $global:LASTEXITCODE = $exitCode
+}
+`
+}
+
+// ElvishInit returns the elvish initialization script
+func ElvishInit() string {
+	return `# wt shell integration for elvish
+fn wt {|@args|
+    var directive-file = (e:mktemp -t wt.XXXXXX)
+    try {
+        e:env WT_DIRECTIVE_FILE=$directive-file WT_SHELL=elvish wt $@args
+        # Apply directives (cd, env vars, on_enter, tmux attach) written by wt
+        eval (slurp < $directive-file)
+    } finally {
+        e:rm -f $directive-file
+    }
+}
+`
+}
+
+// XonshInit returns the xonsh initialization script
+func XonshInit() string {
+	return `# wt shell integration for xonsh
+def _wt(args):
+    import os, shutil, sys, tempfile
+    wt_bin = shutil.which('wt', path=os.pathsep.join($PATH))
+    if wt_bin is None:
+        print('wt: command not found', file=sys.stderr)
+        return 127
+    fd, directive_file = tempfile.mkstemp(prefix='wt.')
+    os.close(fd)
+    try:
+        with ${...}.swap(WT_DIRECTIVE_FILE=directive_file, WT_SHELL='xonsh'):
+            exit_code = ![@(wt_bin) @(args)].returncode
+        # Apply directives (cd, env vars, on_enter, tmux attach) written by wt
+        if exit_code == 0:
+            with open(directive_file) as f:
+                execx(f.read())
+        return exit_code
+    finally:
+        os.remove(directive_file)
+
+aliases['wt'] = _wt
+del _wt
+`
+}
+
 // GetInit returns the initialization script for the given shell
 func GetInit(shell string) (string, error) {
 	switch shell {
@@ -76,7 +170,15 @@ func GetInit(shell string) (string, error) {
 		return ZshInit(), nil
 	case "fish":
 		return FishInit(), nil
+	case "nu", "nushell":
+		return NushellInit(), nil
+	case "pwsh", "powershell":
+		return PowerShellInit(), nil
+	case "elvish":
+		return ElvishInit(), nil
+	case "xonsh":
+		return XonshInit(), nil
 	default:
-		return "", fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish)", shell)
+		return "", fmt.Errorf("unsupported shell: %s (supported: bash, zsh, fish, nu, pwsh, elvish, xonsh)", shell)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 )
 
@@ -105,8 +106,33 @@ func CurrentWindow() string {
 	return strings.TrimSpace(string(output))
 }
 
+// RenameWindow renames a window in the given session
+func RenameWindow(session, windowName, newName string) error {
+	target := session + ":" + windowName
+	return runTmux("rename-window", "-t", target, newName)
+}
+
 // KillWindow kills a window in the given session
 func KillWindow(session, windowName string) error {
 	target := session + ":" + windowName
 	return runTmux("kill-window", "-t", target)
 }
+
+// WindowPanePIDs returns the process IDs of the programs started in each
+// pane of a window, usually shells
+func WindowPanePIDs(session, windowName string) ([]int, error) {
+	target := session + ":" + windowName
+	output, err := exec.Command("tmux", "list-panes", "-t", target, "-F", "#{pane_pid}").Output()
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, line := range strings.Fields(string(output)) {
+		pid, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected pane pid %q", line)
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
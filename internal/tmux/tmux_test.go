@@ -145,3 +145,24 @@ func TestKillWindow(t *testing.T) {
 		t.Error("Window 'to-kill' should not exist after killing")
 	}
 }
+
+func TestRenameWindow(t *testing.T) {
+	sessionName := "wt-test-rename"
+	cleanup := setupTestSession(t, sessionName)
+	defer cleanup()
+
+	if err := CreateWindow(sessionName, "repo:old", "/tmp", ""); err != nil {
+		t.Fatalf("CreateWindow failed: %v", err)
+	}
+
+	if err := RenameWindow(sessionName, "repo:old", "repo:new"); err != nil {
+		t.Fatalf("RenameWindow failed: %v", err)
+	}
+
+	if WindowExists(sessionName, "repo:old") {
+		t.Error("Window 'repo:old' should not exist after renaming")
+	}
+	if !WindowExists(sessionName, "repo:new") {
+		t.Error("Window 'repo:new' should exist after renaming")
+	}
+}
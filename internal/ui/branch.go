@@ -0,0 +1,398 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/roveo/wt/internal/git"
+	"github.com/sahilm/fuzzy"
+)
+
+// BranchChoice is the branch picked in the add workflow
+type BranchChoice struct {
+	// Name is the branch name without the remote
+	Name string
+
+	// Remote is set when a remote-tracking branch was picked
+	Remote string
+
+	// WorktreePath is set when the branch is already checked out in a worktree
+	WorktreePath string
+
+	// New is true when the user typed a name that matches no existing branch
+	New bool
+
+	// Base is the ref to create the branch from: the selected base for new
+	// branches, or remote/name for remote branches
+	Base string
+}
+
+// branchesMsg delivers the branch list, or the error that prevented loading it
+type branchesMsg struct {
+	branches []git.Branch
+	err      error
+}
+
+// loadBranchesCmd lists branches in the background, fetching first if requested
+func loadBranchesCmd(repoPath string, fetch bool) tea.Cmd {
+	return func() tea.Msg {
+		if fetch {
+			if err := git.Fetch(repoPath); err != nil {
+				return branchesMsg{err: fmt.Errorf("fetch failed: %w", err)}
+			}
+		}
+		branches, err := git.ListBranches(repoPath)
+		return branchesMsg{branches: branches, err: err}
+	}
+}
+
+// branchPickerModel fuzzy-matches local and remote branches and lets the
+// user type a new branch name when nothing matches. In base mode the same
+// list is used to pick the ref new branches are created from.
+type branchPickerModel struct {
+	repoPath   string
+	sourceRepo string
+
+	input       textinput.Model
+	allBranches []git.Branch
+	branches    []git.Branch // allBranches without remote duplicates of local ones
+	filtered    []int        // indices into list()
+	matches     []fuzzy.Match
+	cursor      int // 0 is the "create" row when showCreate() is true
+	height      int
+
+	loading bool
+	err     error
+
+	// base is the ref new branches are created from; baseMode is true
+	// while picking it, with the branch query saved in savedQuery
+	base       string
+	baseMode   bool
+	savedQuery string
+
+	action   PickerAction
+	choice   *BranchChoice
+	quitting bool
+}
+
+func newBranchPickerModel(repoPath, sourceRepo, base string) branchPickerModel {
+	ti := textinput.New()
+	ti.Prompt = promptStyle.Render("> ")
+	ti.Placeholder = "type to filter or enter a new branch name"
+	ti.PlaceholderStyle = renderer.NewStyle().Faint(true)
+	ti.TextStyle = renderer.NewStyle()
+	ti.Cursor.Style = renderer.NewStyle().Foreground(promptStyle.GetForeground())
+	ti.Focus()
+	ti.CharLimit = 100
+	ti.Width = 50
+
+	return branchPickerModel{
+		repoPath:   repoPath,
+		sourceRepo: sourceRepo,
+		input:      ti,
+		height:     10,
+		loading:    true,
+		base:       base,
+		action:     ActionNone,
+	}
+}
+
+// list returns the branches currently being picked from
+func (m branchPickerModel) list() []git.Branch {
+	if m.baseMode {
+		return m.allBranches
+	}
+	return m.branches
+}
+
+// setBranches stores the branch list, hiding remote branches that
+// already have a local branch with the same name
+func (m *branchPickerModel) setBranches(branches []git.Branch) {
+	m.allBranches = branches
+	local := make(map[string]bool)
+	for _, b := range branches {
+		if b.Remote == "" {
+			local[b.Name] = true
+		}
+	}
+	m.branches = nil
+	for _, b := range branches {
+		if b.Remote != "" && local[b.Name] {
+			continue
+		}
+		m.branches = append(m.branches, b)
+	}
+	m.updateFilter()
+}
+
+func (m *branchPickerModel) updateFilter() {
+	list := m.list()
+	query := strings.TrimSpace(m.input.Value())
+	if query == "" {
+		m.filtered = make([]int, len(list))
+		for i := range list {
+			m.filtered[i] = i
+		}
+		m.matches = nil
+	} else {
+		refs := make([]string, len(list))
+		for i, b := range list {
+			refs[i] = b.Ref()
+		}
+		m.matches = fuzzy.Find(query, refs)
+		m.filtered = make([]int, len(m.matches))
+		for i, match := range m.matches {
+			m.filtered[i] = match.Index
+		}
+	}
+	if m.cursor >= m.rowCount() {
+		m.cursor = max(0, m.rowCount()-1)
+	}
+}
+
+// showCreate reports whether the "create new branch" row is shown:
+// the input is non-empty and doesn't name an existing branch
+func (m branchPickerModel) showCreate() bool {
+	query := strings.TrimSpace(m.input.Value())
+	if query == "" || m.baseMode {
+		return false
+	}
+	for _, b := range m.branches {
+		if b.Name == query || b.Ref() == query {
+			return false
+		}
+	}
+	return true
+}
+
+// rowCount returns the number of selectable rows
+func (m branchPickerModel) rowCount() int {
+	if m.showCreate() {
+		return len(m.filtered) + 1
+	}
+	return len(m.filtered)
+}
+
+// selected returns the choice for the row under the cursor
+func (m branchPickerModel) selected() *BranchChoice {
+	row := m.cursor
+	if m.showCreate() {
+		if row == 0 {
+			return &BranchChoice{Name: strings.TrimSpace(m.input.Value()), New: true, Base: m.base}
+		}
+		row--
+	}
+	if row < 0 || row >= len(m.filtered) {
+		return nil
+	}
+	b := m.list()[m.filtered[row]]
+	choice := &BranchChoice{Name: b.Name, Remote: b.Remote, WorktreePath: b.WorktreePath}
+	if b.Remote != "" {
+		choice.Base = b.Ref()
+	}
+	return choice
+}
+
+// toggleBaseMode switches between picking the branch and picking its base,
+// keeping the branch query while the base is picked
+func (m *branchPickerModel) toggleBaseMode() {
+	if m.baseMode {
+		m.baseMode = false
+		m.input.SetValue(m.savedQuery)
+	} else {
+		m.baseMode = true
+		m.savedQuery = m.input.Value()
+		m.input.SetValue("")
+	}
+	m.input.CursorEnd()
+	m.cursor = 0
+	m.updateFilter()
+}
+
+func (m branchPickerModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, loadBranchesCmd(m.repoPath, false))
+}
+
+func (m branchPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case branchesMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.setBranches(msg.branches)
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.height = min(msg.Height-4, 15) // Leave room for title, input and help
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			m.action = ActionNone
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+		switch keys.filterAction("branch", msg.String(), m.input.Value()) {
+		case "quit":
+			if m.baseMode {
+				m.toggleBaseMode()
+				return m, nil
+			}
+			m.action = ActionNone
+			m.quitting = true
+			return m, tea.Quit
+
+		case "back":
+			m.action = ActionBack
+			m.quitting = true
+			return m, tea.Quit
+
+		case "base":
+			m.toggleBaseMode()
+			return m, nil
+
+		case "switch":
+			if m.baseMode {
+				if m.cursor < len(m.filtered) {
+					m.base = m.list()[m.filtered[m.cursor]].Ref()
+					m.toggleBaseMode()
+				}
+				return m, nil
+			}
+			if choice := m.selected(); choice != nil {
+				m.choice = choice
+				m.action = ActionSwitch
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+
+		case "fetch":
+			if m.loading {
+				return m, nil
+			}
+			m.loading = true
+			m.err = nil
+			return m, loadBranchesCmd(m.repoPath, true)
+
+		case "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+
+		case "down":
+			if m.cursor < m.rowCount()-1 {
+				m.cursor++
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	m.updateFilter()
+	return m, cmd
+}
+
+func (m branchPickerModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+
+	base := m.base
+	if base == "" {
+		base = "HEAD"
+	}
+	info := helpStyle.Render(fmt.Sprintf("in %s, new branches from %s", m.sourceRepo, base))
+	if m.baseMode {
+		b.WriteString(selectedStyle.Render("Base for new branches:") + " " + info + "\n")
+	} else {
+		b.WriteString(selectedStyle.Render("Branch:") + " " + info + "\n")
+	}
+	b.WriteString(m.input.View() + "\n")
+
+	type row struct{ label, highlighted, suffix string }
+	var rows []row
+	if m.showCreate() {
+		label := "+ create new branch " + strings.TrimSpace(m.input.Value())
+		rows = append(rows, row{label: label, highlighted: normalStyle.Render(label)})
+	}
+	list := m.list()
+	for i, idx := range m.filtered {
+		br := list[idx]
+		r := row{label: br.Ref(), highlighted: normalStyle.Render(br.Ref())}
+		if m.matches != nil && i < len(m.matches) {
+			r.highlighted = highlightMatches(r.label, m.matches[i].MatchedIndexes)
+		}
+		if br.WorktreePath != "" && !m.baseMode {
+			r.suffix = "  " + helpStyle.Render("(checked out at "+br.WorktreePath+")")
+		}
+		rows = append(rows, r)
+	}
+
+	visible := min(len(rows), m.height)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+	for i := start; i < start+visible && i < len(rows); i++ {
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render("> " + rows[i].label))
+		} else {
+			b.WriteString("  " + rows[i].highlighted)
+		}
+		b.WriteString(rows[i].suffix + "\n")
+	}
+
+	switch {
+	case m.loading:
+		b.WriteString(helpStyle.Render("loading branches...") + "\n")
+	case m.err != nil:
+		b.WriteString(helpStyle.Render(fmt.Sprintf("error: %v", m.err)) + "\n")
+	}
+
+	if m.baseMode {
+		b.WriteString(helpStyle.Render(keys.help("branch", map[string]string{
+			"switch": "use as base",
+			"quit":   "cancel",
+		})))
+	} else {
+		b.WriteString(helpStyle.Render(keys.help("branch", map[string]string{
+			"switch": "select",
+			"base":   "base",
+			"fetch":  "fetch",
+			"back":   "back",
+			"quit":   "quit",
+		})))
+	}
+	return b.String()
+}
+
+// PickBranch shows a fuzzy picker over the repo's local and remote branches.
+// base is the initial ref new branches are created from (empty for HEAD);
+// the user can change it with ctrl-b.
+// Returns the chosen branch (nil if cancelled) and the action
+// (ActionBack if user wants to go back).
+func PickBranch(repoPath, sourceRepo, base string) (*BranchChoice, PickerAction, error) {
+	m := newBranchPickerModel(repoPath, sourceRepo, base)
+
+	finalModel, err := runTUI(m)
+	if err != nil {
+		return nil, ActionNone, err
+	}
+
+	result := finalModel.(branchPickerModel)
+
+	if result.action == ActionSwitch {
+		return result.choice, ActionNone, nil
+	}
+
+	return nil, result.action, nil
+}
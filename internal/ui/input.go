@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// inputModel is a single-line text prompt
+type inputModel struct {
+	title     string
+	input     textinput.Model
+	confirmed bool
+	quitting  bool
+}
+
+func (m inputModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m inputModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.quitting = true
+			return m, tea.Quit
+
+		case tea.KeyEnter:
+			m.confirmed = true
+			m.quitting = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m inputModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(selectedStyle.Render(m.title))
+	b.WriteString("\n")
+	b.WriteString(m.input.View())
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("enter:confirm  esc:cancel"))
+	return b.String()
+}
+
+// InputText prompts for a line of text, pre-filled with value. Returns false
+// if the user cancelled.
+func InputText(title, value string) (string, bool, error) {
+	ti := textinput.New()
+	ti.Prompt = "> "
+	ti.PromptStyle = promptStyle
+	ti.SetValue(value)
+	ti.Focus()
+
+	m := inputModel{title: title, input: ti}
+
+	finalModel, err := runTUI(m)
+	if err != nil {
+		return "", false, err
+	}
+
+	result := finalModel.(inputModel)
+	if !result.confirmed {
+		return "", false, nil
+	}
+	return result.input.Value(), true, nil
+}
+
+// ConfirmTyped asks the user to type want to confirm a destructive action.
+// Returns false if they cancelled or typed something else.
+func ConfirmTyped(title, want string) (bool, error) {
+	value, ok, err := InputText(fmt.Sprintf("%s Type %q to confirm:", title, want), "")
+	if err != nil || !ok {
+		return false, err
+	}
+	return strings.TrimSpace(value) == want, nil
+}
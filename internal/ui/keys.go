@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/roveo/wt/internal/config"
+)
+
+// keyMap resolves keys to picker actions per context ("picker", "branch")
+type keyMap struct {
+	bindings config.KeyBindings
+	actions  map[string]map[string]string // context -> key -> action
+}
+
+// keys are the active key bindings, replaced by SetKeys
+var keys = newKeyMap(config.DefaultKeyBindings())
+
+// SetKeys replaces the pickers' key bindings. The bindings must have been
+// resolved by config.Load, which checks them for conflicts.
+func SetKeys(bindings config.KeyBindings) {
+	keys = newKeyMap(bindings)
+}
+
+func newKeyMap(bindings config.KeyBindings) keyMap {
+	km := keyMap{bindings: bindings, actions: make(map[string]map[string]string)}
+	for context, actions := range config.KeyContexts {
+		km.actions[context] = make(map[string]string)
+		for _, action := range actions {
+			for _, key := range bindings[action] {
+				km.actions[context][key] = action
+			}
+		}
+	}
+	return km
+}
+
+// action returns the action bound to a key (tea.KeyMsg.String()) in a
+// context, or "" if the key is unbound
+func (km keyMap) action(context, key string) string {
+	return km.actions[context][key]
+}
+
+// filterAction is action for a picker with a filter input holding filter.
+// Printable keys like space only act while the filter is empty, so they
+// can still be typed into it.
+func (km keyMap) filterAction(context, key, filter string) string {
+	if filter != "" && utf8.RuneCountInString(key) == 1 {
+		return ""
+	}
+	return km.action(context, key)
+}
+
+// help renders the help line entries for a context in "key:label" form,
+// using the first key of each action and skipping actions without a label
+func (km keyMap) help(context string, labels map[string]string) string {
+	var parts []string
+	for _, action := range config.KeyContexts[context] {
+		label, ok := labels[action]
+		if !ok || len(km.bindings[action]) == 0 {
+			continue
+		}
+		parts = append(parts, displayKey(km.bindings[action][0])+":"+label)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// displayKey formats a key the way the help line spells it, e.g. "ctrl-d"
+func displayKey(key string) string {
+	if key == " " {
+		return "space"
+	}
+	if len(key) > 1 {
+		return strings.ReplaceAll(key, "+", "-")
+	}
+	return key
+}
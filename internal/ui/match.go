@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/roveo/wt/internal/db"
+	"github.com/sahilm/fuzzy"
+)
+
+// searchString is what the picker and MatchWorktrees match queries against
+func searchString(wt *db.Worktree) string {
+	return formatWorktreeLabel(wt) + " " + wt.Path
+}
+
+// MatchWorktrees fuzzy-matches query against repo/branch and path the same
+// way the picker filters, returning the matches best first
+func MatchWorktrees(worktrees []*db.Worktree, query string) []*db.Worktree {
+	strs := make([]string, len(worktrees))
+	for i, wt := range worktrees {
+		strs[i] = searchString(wt)
+	}
+	matches := fuzzy.Find(query, strs)
+	result := make([]*db.Worktree, len(matches))
+	for i, match := range matches {
+		result[i] = worktrees[match.Index]
+	}
+	return result
+}
+
+// BestMatch returns the worktree query unambiguously refers to: the only
+// fuzzy match, or the only worktree whose repo/branch, branch or directory
+// name equals the query. Among several exact matches, one in the current
+// repo wins. ok is false if there is no match or several.
+func BestMatch(worktrees []*db.Worktree, query, currentRepoPath string) (wt *db.Worktree, ok bool) {
+	matches := MatchWorktrees(worktrees, query)
+	if len(matches) == 1 {
+		return matches[0], true
+	}
+
+	// Exact names, from most to least specific
+	exact := []func(*db.Worktree) string{
+		func(wt *db.Worktree) string { return wt.RepoName + "/" + wt.Branch },
+		func(wt *db.Worktree) string { return wt.Branch },
+		func(wt *db.Worktree) string { return filepath.Base(wt.Path) },
+	}
+	for _, name := range exact {
+		var found []*db.Worktree
+		for _, m := range matches {
+			if strings.EqualFold(name(m), query) {
+				found = append(found, m)
+			}
+		}
+		if len(found) == 1 {
+			return found[0], true
+		}
+		if len(found) > 1 {
+			var inRepo []*db.Worktree
+			for _, f := range found {
+				if f.RepoPath == currentRepoPath {
+					inRepo = append(inRepo, f)
+				}
+			}
+			if len(inRepo) == 1 {
+				return inRepo[0], true
+			}
+			return nil, false
+		}
+	}
+	return nil, false
+}
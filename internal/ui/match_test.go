@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/roveo/wt/internal/db"
+)
+
+func TestBestMatch(t *testing.T) {
+	worktrees := []*db.Worktree{
+		{RepoName: "api", RepoPath: "/src/api", Branch: "main", Path: "/src/api", IsMain: true},
+		{RepoName: "api", RepoPath: "/src/api", Branch: "feature/auth", Path: "/src/api.worktrees/feature-auth"},
+		{RepoName: "web", RepoPath: "/src/web", Branch: "main", Path: "/src/web", IsMain: true},
+		{RepoName: "web", RepoPath: "/src/web", Branch: "fix-login", Path: "/src/web.worktrees/fix-login"},
+	}
+
+	tests := []struct {
+		query, currentRepo string
+		want               string // path, or "" when ambiguous
+	}{
+		{"auth", "", "/src/api.worktrees/feature-auth"},   // single fuzzy match
+		{"web/main", "", "/src/web"},                      // exact repo/branch
+		{"main", "", ""},                                  // branch in two repos
+		{"main", "/src/api", "/src/api"},                  // current repo breaks the tie
+		{"fix-login", "", "/src/web.worktrees/fix-login"}, // exact branch
+		{"zzz", "", ""},                                   // no match
+	}
+
+	for _, tt := range tests {
+		wt, ok := BestMatch(worktrees, tt.query, tt.currentRepo)
+		got := ""
+		if ok {
+			got = wt.Path
+		}
+		if got != tt.want {
+			t.Errorf("BestMatch(%q, %q) = %q, want %q", tt.query, tt.currentRepo, got, tt.want)
+		}
+	}
+}
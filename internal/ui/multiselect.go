@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SelectItem is a row in a multi-select list
+type SelectItem struct {
+	Label    string
+	Detail   string // shown dimmed after the label
+	Selected bool   // initial selection state
+	Disabled bool   // shown but cannot be selected
+}
+
+// multiSelectModel is a checklist for confirming a set of items
+type multiSelectModel struct {
+	title     string
+	items     []SelectItem
+	cursor    int
+	confirmed bool
+	quitting  bool
+	height    int
+}
+
+func (m multiSelectModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m multiSelectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = min(msg.Height-3, 20) // Leave room for title and help
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			m.quitting = true
+			return m, tea.Quit
+
+		case "enter":
+			m.confirmed = true
+			m.quitting = true
+			return m, tea.Quit
+
+		case "up", "k", "ctrl+p":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+
+		case "down", "j", "ctrl+n":
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+
+		case " ", "tab":
+			if item := &m.items[m.cursor]; !item.Disabled {
+				item.Selected = !item.Selected
+			}
+
+		case "a":
+			// Select all if anything selectable is unselected, otherwise clear
+			selectAll := false
+			for _, item := range m.items {
+				if !item.Disabled && !item.Selected {
+					selectAll = true
+				}
+			}
+			for i := range m.items {
+				if !m.items[i].Disabled {
+					m.items[i].Selected = selectAll
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m multiSelectModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(selectedStyle.Render(m.title))
+	b.WriteString("\n")
+
+	visible := min(len(m.items), m.height)
+	start := 0
+	if m.cursor >= visible {
+		start = m.cursor - visible + 1
+	}
+
+	for i := start; i < start+visible && i < len(m.items); i++ {
+		item := m.items[i]
+		check := "[ ]"
+		if item.Selected {
+			check = "[x]"
+		} else if item.Disabled {
+			check = "[-]"
+		}
+
+		line := check + " " + item.Label
+		if i == m.cursor {
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else if item.Disabled {
+			b.WriteString(helpStyle.Render("  " + line))
+		} else {
+			b.WriteString(normalStyle.Render("  " + line))
+		}
+		if item.Detail != "" {
+			b.WriteString("  " + helpStyle.Render(item.Detail))
+		}
+		b.WriteString("\n")
+	}
+
+	selected := 0
+	for _, item := range m.items {
+		if item.Selected {
+			selected++
+		}
+	}
+	help := fmt.Sprintf("%d selected  space:toggle  a:all  enter:confirm  esc:cancel", selected)
+	b.WriteString(helpStyle.Render(help))
+	return b.String()
+}
+
+// MultiSelect shows a checklist and returns the indices of the selected items.
+// Returns nil if the user cancelled, and an empty slice if they confirmed
+// without selecting anything.
+func MultiSelect(title string, items []SelectItem) ([]int, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	m := multiSelectModel{
+		title:  title,
+		items:  append([]SelectItem(nil), items...),
+		height: 10,
+	}
+
+	finalModel, err := runTUI(m)
+	if err != nil {
+		return nil, err
+	}
+
+	result := finalModel.(multiSelectModel)
+	if !result.confirmed {
+		return nil, nil
+	}
+
+	selected := []int{}
+	for i, item := range result.items {
+		if item.Selected {
+			selected = append(selected, i)
+		}
+	}
+	return selected, nil
+}
@@ -3,13 +3,13 @@ package ui
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
-	"github.com/muesli/termenv"
 	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
 	"github.com/sahilm/fuzzy"
 	"golang.org/x/term"
 )
@@ -22,44 +22,32 @@ const (
 	ActionSwitch
 	ActionAdd
 	ActionBack   // Return from add mode to worktree list
-	ActionDelete // Delete the selected worktree
+	ActionDelete // Delete the selected worktrees
+	ActionRun    // Run a command in each selected worktree
+	ActionOpen   // Open a tmux window for each selected worktree
+	ActionRename // Rename the selected worktree's branch or move it
 )
 
 // PickerResult contains the result of the picker
 type PickerResult struct {
 	Action   PickerAction
-	Worktree *db.Worktree
+	Worktree *db.Worktree // the worktree under the cursor
+
+	// Worktrees are the marked worktrees in list order, or just the one
+	// under the cursor if none are marked
+	Worktrees []*db.Worktree
 }
 
-// renderer uses stderr to avoid polluting stdout with terminal escape sequences
-// We use ANSI profile to avoid terminal queries for color support detection
-var renderer *lipgloss.Renderer
-
-// Styles using terminal theme colors (ANSI)
-var (
-	selectedStyle lipgloss.Style
-	normalStyle   lipgloss.Style
-	helpStyle     lipgloss.Style
-	matchStyle    lipgloss.Style
-	promptStyle   lipgloss.Style
-)
+// PickerOptions configures the worktree picker
+type PickerOptions struct {
+	// Sort is the initial sort mode; it can be cycled at runtime
+	Sort db.SortMode
+
+	// CurrentRepoPath's worktrees are listed first when sorting by name
+	CurrentRepoPath string
 
-func init() {
-	// Set the default termenv output to stderr BEFORE any terminal queries happen
-	// This prevents escape sequences from being written to stdout
-	output := termenv.NewOutput(os.Stderr, termenv.WithProfile(termenv.ANSI256))
-	termenv.SetDefaultOutput(output)
-
-	// Create lipgloss renderer using stderr
-	renderer = lipgloss.NewRenderer(os.Stderr, termenv.WithProfile(termenv.ANSI256))
-	lipgloss.SetDefaultRenderer(renderer)
-
-	// Initialize styles
-	selectedStyle = renderer.NewStyle().Foreground(lipgloss.ANSIColor(6)).Bold(true)   // cyan
-	normalStyle = renderer.NewStyle()                                                  // default
-	helpStyle = renderer.NewStyle().Faint(true)                                        // dimmed
-	matchStyle = renderer.NewStyle().Foreground(lipgloss.ANSIColor(6)).Underline(true) // cyan
-	promptStyle = renderer.NewStyle().Foreground(lipgloss.ANSIColor(6))                // cyan
+	// Query pre-fills the filter input
+	Query string
 }
 
 // pickerModel is a minimal fzf-like picker
@@ -72,21 +60,65 @@ type pickerModel struct {
 	action    PickerAction
 	quitting  bool
 	height    int
+	width     int
+
+	// marked holds the paths of worktrees selected for bulk actions
+	marked map[string]bool
+
+	// statuses holds git status per worktree path, filled in asynchronously
+	statuses map[string]*git.WorktreeStatus
+
+	// showPreview toggles the preview pane; previews caches it per worktree path
+	showPreview bool
+	previews    map[string]*previewEntry
+
+	sortMode        db.SortMode
+	currentRepoPath string
+}
+
+// statusMsg delivers the git status of a single worktree
+type statusMsg struct {
+	path   string
+	status *git.WorktreeStatus
+}
+
+// statusSem bounds the number of concurrent git status subprocesses
+var statusSem = make(chan struct{}, 8)
+
+// loadStatusCmd loads the git status of a worktree in the background
+func loadStatusCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		statusSem <- struct{}{}
+		defer func() { <-statusSem }()
+
+		status, err := git.GetWorktreeStatus(path)
+		if err != nil {
+			return nil
+		}
+		return statusMsg{path: path, status: status}
+	}
 }
 
-func newPickerModel(worktrees []*db.Worktree) pickerModel {
+func newPickerModel(worktrees []*db.Worktree, opts PickerOptions) pickerModel {
 	ti := textinput.New()
 	ti.Prompt = "> "
 	ti.PromptStyle = promptStyle
 	ti.Focus()
 
+	sortMode := opts.Sort
+	if sortMode == "" {
+		sortMode = db.SortName
+	}
+	worktrees = slices.Clone(worktrees)
+	db.SortWorktrees(worktrees, sortMode, opts.CurrentRepoPath)
+
 	// Initialize with all items
 	filtered := make([]int, len(worktrees))
 	for i := range worktrees {
 		filtered[i] = i
 	}
 
-	return pickerModel{
+	m := pickerModel{
 		worktrees: worktrees,
 		filtered:  filtered,
 		matches:   nil,
@@ -94,14 +126,25 @@ func newPickerModel(worktrees []*db.Worktree) pickerModel {
 		input:     ti,
 		action:    ActionNone,
 		height:    10,
+		marked:    make(map[string]bool),
+		statuses:  make(map[string]*git.WorktreeStatus),
+		previews:  make(map[string]*previewEntry),
+
+		sortMode:        sortMode,
+		currentRepoPath: opts.CurrentRepoPath,
 	}
+	if opts.Query != "" {
+		m.input.SetValue(opts.Query)
+		m.updateFilter()
+	}
+	return m
 }
 
 // worktreeStrings returns searchable strings for fuzzy matching
 func (m *pickerModel) worktreeStrings() []string {
 	strs := make([]string, len(m.worktrees))
 	for i, wt := range m.worktrees {
-		strs[i] = formatWorktreeLabel(wt) + " " + wt.Path
+		strs[i] = searchString(wt)
 	}
 	return strs
 }
@@ -129,33 +172,117 @@ func (m *pickerModel) updateFilter() {
 	}
 }
 
+// toggleMark marks or unmarks the worktree under the cursor
+func (m *pickerModel) toggleMark() {
+	if len(m.filtered) == 0 {
+		return
+	}
+	path := m.worktrees[m.filtered[m.cursor]].Path
+	if m.marked[path] {
+		delete(m.marked, path)
+	} else {
+		m.marked[path] = true
+	}
+}
+
+// toggleMarkAll marks every filtered worktree, or unmarks them if they
+// are all marked already
+func (m *pickerModel) toggleMarkAll() {
+	all := true
+	for _, idx := range m.filtered {
+		if !m.marked[m.worktrees[idx].Path] {
+			all = false
+			break
+		}
+	}
+	for _, idx := range m.filtered {
+		if all {
+			delete(m.marked, m.worktrees[idx].Path)
+		} else {
+			m.marked[m.worktrees[idx].Path] = true
+		}
+	}
+}
+
+// selection returns the marked worktrees in list order, falling back to
+// the one under the cursor
+func (m pickerModel) selection() []*db.Worktree {
+	var selected []*db.Worktree
+	for _, wt := range m.worktrees {
+		if m.marked[wt.Path] {
+			selected = append(selected, wt)
+		}
+	}
+	if len(selected) == 0 && len(m.filtered) > 0 {
+		selected = append(selected, m.worktrees[m.filtered[m.cursor]])
+	}
+	return selected
+}
+
 func (m pickerModel) Init() tea.Cmd {
-	return textinput.Blink
+	cmds := []tea.Cmd{textinput.Blink}
+	for _, wt := range m.worktrees {
+		cmds = append(cmds, loadStatusCmd(wt.Path))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case statusMsg:
+		m.statuses[msg.path] = msg.status
+		return m, nil
+
+	case previewMsg:
+		m.previews[msg.path] = &previewEntry{details: msg.details, err: msg.err}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.height = min(msg.Height-3, 20) // Leave room for input and help
+		m.width = msg.Width
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
+		if msg.Type == tea.KeyCtrlC {
+			m.action = ActionNone
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+		switch action := keys.filterAction("picker", msg.String(), m.input.Value()); action {
+		case "quit":
 			m.action = ActionNone
 			m.quitting = true
 			return m, tea.Quit
 
-		case tea.KeyTab:
+		case "add":
 			m.action = ActionAdd
 			m.quitting = true
 			return m, tea.Quit
 
-		case tea.KeyCtrlD:
-			// Delete selected worktree (if not main)
+		case "mark":
+			// Mark and move on, like fzf
+			m.toggleMark()
+			if m.cursor < len(m.filtered)-1 {
+				m.cursor++
+			}
+			return m, m.previewCmd()
+
+		case "mark_all":
+			m.toggleMarkAll()
+			return m, nil
+
+		case "rename":
 			if len(m.filtered) > 0 {
-				idx := m.filtered[m.cursor]
-				wt := m.worktrees[idx]
+				m.action = ActionRename
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+
+		case "delete":
+			// Delete the selection unless it's only main worktrees
+			for _, wt := range m.selection() {
 				if !wt.IsMain {
 					m.action = ActionDelete
 					m.quitting = true
@@ -164,24 +291,46 @@ func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
-		case tea.KeyEnter:
+		case "run", "tmux":
+			if len(m.filtered) > 0 {
+				m.action = ActionRun
+				if action == "tmux" {
+					m.action = ActionOpen
+				}
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+
+		case "switch":
 			if len(m.filtered) > 0 {
 				m.action = ActionSwitch
 				m.quitting = true
 				return m, tea.Quit
 			}
+			return m, nil
 
-		case tea.KeyUp, tea.KeyCtrlP:
+		case "sort":
+			m.sortMode = m.sortMode.Next()
+			db.SortWorktrees(m.worktrees, m.sortMode, m.currentRepoPath)
+			m.updateFilter()
+			return m, m.previewCmd()
+
+		case "preview":
+			m.showPreview = !m.showPreview
+			return m, m.previewCmd()
+
+		case "up":
 			if m.cursor > 0 {
 				m.cursor--
 			}
-			return m, nil
+			return m, m.previewCmd()
 
-		case tea.KeyDown, tea.KeyCtrlN:
+		case "down":
 			if m.cursor < len(m.filtered)-1 {
 				m.cursor++
 			}
-			return m, nil
+			return m, m.previewCmd()
 		}
 	}
 
@@ -190,7 +339,7 @@ func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.input, cmd = m.input.Update(msg)
 	m.updateFilter()
 
-	return m, cmd
+	return m, tea.Batch(cmd, m.previewCmd())
 }
 
 func (m pickerModel) View() string {
@@ -200,12 +349,52 @@ func (m pickerModel) View() string {
 
 	var b strings.Builder
 
+	listHeight := m.height
+	stacked := m.showPreview && m.width < sideBySideMinWidth
+	if stacked {
+		listHeight = max(1, m.height-stackedPreviewHeight(m.height)-1)
+	}
+
+	list := m.listView(listHeight)
+	if m.showPreview {
+		b.WriteString(m.joinWithPreview(list, listHeight))
+	} else {
+		b.WriteString(list)
+	}
+
+	// Help line
+	countInfo := fmt.Sprintf("%d/%d", len(m.filtered), len(m.worktrees))
+	if len(m.marked) > 0 {
+		countInfo += fmt.Sprintf(" (%d marked)", len(m.marked))
+	}
+	help := helpStyle.Render(countInfo + "  " + keys.help("picker", map[string]string{
+		"switch":   "select",
+		"mark":     "mark",
+		"mark_all": "mark all",
+		"add":      "add",
+		"rename":   "rename",
+		"delete":   "delete",
+		"run":      "run",
+		"tmux":     "tmux",
+		"preview":  "preview",
+		"sort":     "sort(" + string(m.sortMode) + ")",
+		"quit":     "quit",
+	}))
+	b.WriteString(help)
+
+	return b.String()
+}
+
+// listView renders the input line and up to height worktree rows
+func (m pickerModel) listView(height int) string {
+	var b strings.Builder
+
 	// Input line
 	b.WriteString(m.input.View())
 	b.WriteString("\n")
 
 	// Items
-	visible := min(len(m.filtered), m.height)
+	visible := min(len(m.filtered), height)
 	start := 0
 	if m.cursor >= visible {
 		start = m.cursor - visible + 1
@@ -214,27 +403,44 @@ func (m pickerModel) View() string {
 	for i := start; i < start+visible && i < len(m.filtered); i++ {
 		idx := m.filtered[i]
 		wt := m.worktrees[idx]
-		label := formatWorktreeLabel(wt)
+		// The [main] marker has its own color, so render it apart from
+		// the repo/branch label
+		label := wt.RepoName + "/" + wt.Branch
+		marker := ""
+		if wt.IsMain {
+			marker = " [main]"
+		}
+
+		mark := " "
+		if m.marked[wt.Path] {
+			mark = markStyle.Render("*")
+		}
 
 		if i == m.cursor {
-			b.WriteString(selectedStyle.Render("> " + label))
+			b.WriteString(selectedStyle.Render(">") + mark + selectedStyle.Render(label))
+			if marker != "" {
+				b.WriteString(mainStyle.Inherit(selectedStyle).Render(marker))
+			}
 		} else {
 			// Apply match highlighting if we have matches
 			if m.matches != nil && i < len(m.matches) {
 				highlighted := highlightMatches(label, m.matches[i].MatchedIndexes)
-				b.WriteString("  " + highlighted)
+				b.WriteString(" " + mark + highlighted)
 			} else {
-				b.WriteString(normalStyle.Render("  " + label))
+				b.WriteString(" " + mark + normalStyle.Render(label))
+			}
+			if marker != "" {
+				b.WriteString(mainStyle.Render(marker))
+			}
+		}
+		if status, ok := m.statuses[wt.Path]; ok {
+			if summary := formatStatus(status); summary != "" {
+				b.WriteString("  " + helpStyle.Render(summary))
 			}
 		}
 		b.WriteString("\n")
 	}
 
-	// Help line
-	countInfo := fmt.Sprintf("%d/%d", len(m.filtered), len(m.worktrees))
-	help := helpStyle.Render(countInfo + "  enter:select  tab:add  ctrl-d:delete  esc:quit")
-	b.WriteString(help)
-
 	return b.String()
 }
 
@@ -260,37 +466,40 @@ func highlightMatches(s string, indices []int) string {
 	return b.String()
 }
 
+// runTUI runs a bubbletea program on stderr. Stdout is left to wt's own
+// output, such as the directives printed when no shell wrapper is set up,
+// so anything written to it while the TUI is up is sent to stderr as well.
+func runTUI(m tea.Model) (tea.Model, error) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	return tea.NewProgram(m, tea.WithOutput(os.Stderr)).Run()
+}
+
 // PickWorktree shows an interactive picker for worktrees
 // Returns the selected worktree and the action (switch or add)
-func PickWorktree(worktrees []*db.Worktree) (*PickerResult, error) {
+func PickWorktree(worktrees []*db.Worktree, opts PickerOptions) (*PickerResult, error) {
 	if len(worktrees) == 0 {
 		return &PickerResult{Action: ActionAdd}, nil
 	}
 
-	m := newPickerModel(worktrees)
-
-	// Redirect stdout fd to stderr during TUI to prevent terminal escape sequences
-	// from polluting stdout (which is used for the cd command)
-	stdout := os.Stdout
-	os.Stdout = os.Stderr
-	defer func() { os.Stdout = stdout }()
-
-	// Use stderr for TUI output so stdout is clean for cd command
-	p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
+	m := newPickerModel(worktrees, opts)
 
-	finalModel, err := p.Run()
+	finalModel, err := runTUI(m)
 	if err != nil {
 		return nil, err
 	}
 
 	result := finalModel.(pickerModel)
 
-	// Return the selected worktree for actions that need it
-	if (result.action == ActionSwitch || result.action == ActionDelete || result.action == ActionAdd) && len(result.filtered) > 0 {
+	// Return the selected worktrees for actions that need them
+	if result.action != ActionNone && len(result.filtered) > 0 {
 		idx := result.filtered[result.cursor]
 		return &PickerResult{
-			Action:   result.action,
-			Worktree: result.worktrees[idx],
+			Action:    result.action,
+			Worktree:  result.worktrees[idx],
+			Worktrees: result.selection(),
 		}, nil
 	}
 
@@ -316,18 +525,40 @@ func formatWorktreeLabel(wt *db.Worktree) string {
 	return sb.String()
 }
 
-// PickWorktreeSimple shows a simple worktree picker without Tab functionality
+// formatStatus formats a compact git status summary for a worktree row:
+// !conflicts +staged ~modified ?untracked ↑ahead ↓behind $stashes
+func formatStatus(status *git.WorktreeStatus) string {
+	var parts []string
+	counters := []struct {
+		symbol string
+		count  int
+	}{
+		{"!", status.Conflicts},
+		{"+", status.Staged},
+		{"~", status.Modified},
+		{"?", status.Untracked},
+		{"↑", status.Ahead},
+		{"↓", status.Behind},
+		{"$", status.Stashes},
+	}
+	for _, c := range counters {
+		if c.count > 0 {
+			parts = append(parts, fmt.Sprintf("%s%d", c.symbol, c.count))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// PickWorktreeSimple shows a simple worktree picker without the add workflow
 // Used by remove command where we don't need the add workflow
 func PickWorktreeSimple(worktrees []*db.Worktree) (*db.Worktree, error) {
 	if len(worktrees) == 0 {
 		return nil, nil
 	}
 
-	// Use the same fzf-like picker but without tab=add functionality
-	m := newPickerModel(worktrees)
-	p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
-
-	finalModel, err := p.Run()
+	// Use the same fzf-like picker but without the add workflow
+	m := newPickerModel(worktrees, PickerOptions{})
+	finalModel, err := runTUI(m)
 	if err != nil {
 		return nil, err
 	}
@@ -342,102 +573,6 @@ func PickWorktreeSimple(worktrees []*db.Worktree) (*db.Worktree, error) {
 	return nil, nil
 }
 
-// inputBranchModel is a simple text input with back support
-type inputBranchModel struct {
-	input        textinput.Model
-	action       PickerAction
-	quitting     bool
-	sourceRepo   string
-	sourceBranch string
-}
-
-func newInputBranchModel(placeholder, sourceRepo, sourceBranch string) inputBranchModel {
-	ti := textinput.New()
-	ti.Prompt = promptStyle.Render("> ")
-	ti.Placeholder = placeholder
-	ti.PlaceholderStyle = renderer.NewStyle().Faint(true)
-	ti.TextStyle = renderer.NewStyle()
-	ti.Cursor.Style = renderer.NewStyle().Foreground(lipgloss.ANSIColor(6))
-	ti.Focus()
-	ti.CharLimit = 100
-	ti.Width = 50
-
-	return inputBranchModel{
-		input:        ti,
-		action:       ActionNone,
-		sourceRepo:   sourceRepo,
-		sourceBranch: sourceBranch,
-	}
-}
-
-func (m inputBranchModel) Init() tea.Cmd {
-	return textinput.Blink
-}
-
-func (m inputBranchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeyCtrlC, tea.KeyEsc:
-			m.action = ActionNone
-			m.quitting = true
-			return m, tea.Quit
-
-		case tea.KeyTab:
-			m.action = ActionBack
-			m.quitting = true
-			return m, tea.Quit
-
-		case tea.KeyEnter:
-			m.action = ActionSwitch
-			m.quitting = true
-			return m, tea.Quit
-		}
-	}
-
-	var cmd tea.Cmd
-	m.input, cmd = m.input.Update(msg)
-	return m, cmd
-}
-
-func (m inputBranchModel) View() string {
-	if m.quitting {
-		return ""
-	}
-
-	source := helpStyle.Render(fmt.Sprintf("from %s/%s", m.sourceRepo, m.sourceBranch))
-	title := selectedStyle.Render("New branch name:") + " " + source
-	help := helpStyle.Render("enter:create  tab:back  esc:quit")
-	return fmt.Sprintf("%s\n%s\n\n%s", title, m.input.View(), help)
-}
-
-// InputBranch prompts for a branch name
-// sourceRepo and sourceBranch are displayed to show where the worktree will be created from
-// Returns the branch name and action (ActionBack if user wants to go back)
-func InputBranch(placeholder, sourceRepo, sourceBranch string) (string, PickerAction, error) {
-	m := newInputBranchModel(placeholder, sourceRepo, sourceBranch)
-
-	// Redirect stdout fd to stderr during TUI to prevent terminal escape sequences
-	// from polluting stdout (which is used for the cd command)
-	stdout := os.Stdout
-	os.Stdout = os.Stderr
-	defer func() { os.Stdout = stdout }()
-
-	p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
-	finalModel, err := p.Run()
-	if err != nil {
-		return "", ActionNone, err
-	}
-
-	result := finalModel.(inputBranchModel)
-
-	if result.action == ActionSwitch {
-		return strings.TrimSpace(result.input.Value()), ActionNone, nil
-	}
-
-	return "", result.action, nil
-}
-
 // Confirm shows a simple confirmation prompt
 // Press enter to confirm, any other key to cancel
 func Confirm(message string) (bool, error) {
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/roveo/wt/internal/db"
+	"github.com/roveo/wt/internal/git"
+)
+
+func TestPickerMarks(t *testing.T) {
+	worktrees := []*db.Worktree{
+		{RepoName: "api", Branch: "main", Path: "/src/api", IsMain: true},
+		{RepoName: "api", Branch: "auth", Path: "/src/api.worktrees/auth"},
+		{RepoName: "web", Branch: "auth", Path: "/src/web.worktrees/auth"},
+	}
+	m := newPickerModel(worktrees, PickerOptions{})
+
+	paths := func(wts []*db.Worktree) []string {
+		var p []string
+		for _, wt := range wts {
+			p = append(p, wt.Path)
+		}
+		return p
+	}
+	press := func(key tea.KeyType) {
+		model, _ := m.Update(tea.KeyMsg{Type: key})
+		m = model.(pickerModel)
+	}
+
+	// Without marks the selection is the worktree under the cursor
+	if got := paths(m.selection()); len(got) != 1 || got[0] != "/src/api" {
+		t.Fatalf("selection = %v, want the cursor row", got)
+	}
+
+	// Tab marks and moves down
+	press(tea.KeyTab)
+	press(tea.KeyTab)
+	if got := paths(m.selection()); len(got) != 2 || got[1] != "/src/api.worktrees/auth" {
+		t.Fatalf("selection = %v, want the first two rows", got)
+	}
+	press(tea.KeyUp)
+	press(tea.KeySpace)
+	if got := paths(m.selection()); len(got) != 1 || got[0] != "/src/api" {
+		t.Fatalf("selection = %v, want the unmarked row dropped", got)
+	}
+
+	// Once the filter has text, space is typed into it rather than marking
+	m.input.SetValue("api")
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = model.(pickerModel)
+	if m.input.Value() != "api " {
+		t.Fatalf("filter = %q, want a space typed", m.input.Value())
+	}
+	if got := paths(m.selection()); len(got) != 1 || got[0] != "/src/api" {
+		t.Fatalf("selection = %v, want nothing marked by space", got)
+	}
+
+	// ctrl-a marks only the filtered rows, then unmarks them
+	m.input.SetValue("web")
+	m.updateFilter()
+	press(tea.KeyCtrlA)
+	if got := paths(m.selection()); len(got) != 2 || got[1] != "/src/web.worktrees/auth" {
+		t.Fatalf("selection = %v, want api main and web/auth", got)
+	}
+	press(tea.KeyCtrlA)
+	if got := paths(m.selection()); len(got) != 1 || got[0] != "/src/api" {
+		t.Fatalf("selection = %v, want web/auth unmarked", got)
+	}
+}
+
+func TestPickerPreviewCache(t *testing.T) {
+	worktrees := []*db.Worktree{
+		{RepoName: "api", Branch: "main", Path: "/src/api", IsMain: true},
+		{RepoName: "api", Branch: "auth", Path: "/src/api.worktrees/auth"},
+	}
+	m := newPickerModel(worktrees, PickerOptions{})
+	m.showPreview = true
+
+	// Failed loads are shown but retried
+	model, _ := m.Update(previewMsg{path: "/src/api", err: errors.New("gone")})
+	m = model.(pickerModel)
+	if m.previews["/src/api"].err == nil {
+		t.Fatal("want the error kept for display")
+	}
+	if m.previewCmd() == nil {
+		t.Fatal("want a failed preview reloaded")
+	}
+
+	// Loaded previews are cached for the picker's lifetime
+	model, _ = m.Update(previewMsg{path: "/src/api.worktrees/auth", details: &git.WorktreeDetails{}})
+	m = model.(pickerModel)
+	m.cursor = 1
+	if m.previewCmd() != nil {
+		t.Fatal("want a loaded preview cached")
+	}
+
+	// The picker is reopened after an action, with nothing cached
+	if len(newPickerModel(worktrees, PickerOptions{}).previews) != 0 {
+		t.Fatal("want a new picker to load its previews afresh")
+	}
+}
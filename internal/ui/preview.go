@@ -0,0 +1,155 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/roveo/wt/internal/git"
+)
+
+// previewLogLimit is the number of recent commits shown in the preview
+const previewLogLimit = 10
+
+// sideBySideMinWidth is the terminal width from which the preview is shown
+// next to the list instead of below it
+const sideBySideMinWidth = 100
+
+// previewEntry is a cached preview for one worktree
+type previewEntry struct {
+	loading bool
+	details *git.WorktreeDetails
+	err     error
+}
+
+// previewMsg delivers the preview details of a single worktree
+type previewMsg struct {
+	path    string
+	details *git.WorktreeDetails
+	err     error
+}
+
+// loadPreviewCmd loads preview details for a worktree in the background
+func loadPreviewCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		details, err := git.GetWorktreeDetails(path, previewLogLimit)
+		return previewMsg{path: path, details: details, err: err}
+	}
+}
+
+// previewCmd starts loading the preview of the highlighted worktree
+// unless the preview is hidden or already cached. Failed loads aren't
+// cached and are retried.
+func (m pickerModel) previewCmd() tea.Cmd {
+	if !m.showPreview || len(m.filtered) == 0 {
+		return nil
+	}
+	path := m.worktrees[m.filtered[m.cursor]].Path
+	if entry, ok := m.previews[path]; ok && entry.err == nil {
+		return nil
+	}
+	m.previews[path] = &previewEntry{loading: true}
+	return loadPreviewCmd(path)
+}
+
+// previewView renders the preview of the highlighted worktree,
+// clipped to the given size
+func (m pickerModel) previewView(width, height int) string {
+	if len(m.filtered) == 0 || width <= 0 || height <= 0 {
+		return ""
+	}
+	path := m.worktrees[m.filtered[m.cursor]].Path
+
+	var lines []string
+	entry := m.previews[path]
+	switch {
+	case entry == nil || entry.loading:
+		lines = []string{helpStyle.Render("Loading...")}
+	case entry.err != nil:
+		lines = []string{path, "", helpStyle.Render(fmt.Sprintf("error: %v", entry.err))}
+	default:
+		lines = formatPreview(entry.details)
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	clip := renderer.NewStyle().MaxWidth(width)
+	for i, line := range lines {
+		lines[i] = clip.Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatPreview formats worktree details as preview lines
+func formatPreview(d *git.WorktreeDetails) []string {
+	lines := []string{selectedStyle.Render(d.Path)}
+
+	upstream := d.Upstream
+	if upstream == "" {
+		upstream = "none"
+	}
+	lines = append(lines, helpStyle.Render("upstream: ")+upstream)
+
+	if !d.LastCommitDate.IsZero() {
+		lines = append(lines, helpStyle.Render("last commit: ")+
+			fmt.Sprintf("%s (%s)", FormatAge(d.LastCommitDate), d.LastCommitDate.Format("2006-01-02 15:04")))
+	}
+
+	lines = append(lines, "", helpStyle.Render("Status:"))
+	if len(d.Status) == 0 {
+		lines = append(lines, "clean")
+	}
+	lines = append(lines, d.Status...)
+
+	lines = append(lines, "", helpStyle.Render("Recent commits:"))
+	lines = append(lines, d.Log...)
+	return lines
+}
+
+// FormatAge formats the time since t in a short human-readable form
+func FormatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d minutes ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	}
+}
+
+// joinWithPreview lays out the list and preview side-by-side on wide
+// terminals and stacked on narrow ones
+func (m pickerModel) joinWithPreview(list string, listHeight int) string {
+	if m.width >= sideBySideMinWidth {
+		listWidth := m.width / 2
+		previewWidth := m.width - listWidth - 3
+		left := renderer.NewStyle().MaxWidth(listWidth).Render(strings.TrimSuffix(list, "\n"))
+		right := renderer.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderLeft(true).
+			BorderForeground(lipgloss.ANSIColor(8)).
+			PaddingLeft(1).
+			Render(m.previewView(previewWidth, listHeight+1))
+		return lipgloss.JoinHorizontal(lipgloss.Top, left, right) + "\n"
+	}
+
+	width := m.width
+	if width == 0 {
+		width = 80
+	}
+	separator := helpStyle.Render(strings.Repeat("─", width))
+	return list + separator + "\n" + m.previewView(width, stackedPreviewHeight(m.height)) + "\n"
+}
+
+// stackedPreviewHeight returns how many lines the preview gets when it is
+// stacked below a list of the given height
+func stackedPreviewHeight(height int) int {
+	return max(3, height/2)
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/roveo/wt/internal/config"
+)
+
+// renderer uses stderr to avoid polluting stdout with terminal escape sequences
+var renderer *lipgloss.Renderer
+
+// Styles, set from the theme by SetTheme
+var (
+	selectedStyle lipgloss.Style
+	normalStyle   lipgloss.Style
+	helpStyle     lipgloss.Style
+	matchStyle    lipgloss.Style
+	promptStyle   lipgloss.Style
+	markStyle     lipgloss.Style
+	mainStyle     lipgloss.Style
+)
+
+func init() {
+	// Detect the color profile from stderr's environment (TERM, COLORTERM,
+	// NO_COLOR, CLICOLOR_FORCE). This never queries the terminal, so no
+	// escape sequences can end up on stdout.
+	profile := termenv.NewOutput(os.Stderr).EnvColorProfile()
+
+	// Set the default termenv output to stderr BEFORE any terminal queries happen
+	// This prevents escape sequences from being written to stdout
+	output := termenv.NewOutput(os.Stderr, termenv.WithProfile(profile))
+	termenv.SetDefaultOutput(output)
+
+	// Create lipgloss renderer using stderr
+	renderer = lipgloss.NewRenderer(os.Stderr, termenv.WithProfile(profile))
+	lipgloss.SetDefaultRenderer(renderer)
+
+	SetTheme(config.DefaultConfig().Theme)
+}
+
+// SetTheme replaces the colors of all pickers and prompts. The colors must
+// have been validated by config.Load.
+func SetTheme(theme config.ThemeConfig) {
+	selectedStyle = renderer.NewStyle().Foreground(themeColor(theme.Selected)).Bold(true)
+	normalStyle = renderer.NewStyle()
+	matchStyle = renderer.NewStyle().Foreground(themeColor(theme.Match)).Underline(true)
+	promptStyle = renderer.NewStyle().Foreground(themeColor(theme.Prompt))
+	markStyle = renderer.NewStyle().Foreground(themeColor(theme.Mark)).Bold(true)
+	mainStyle = renderer.NewStyle().Foreground(themeColor(theme.Main))
+
+	// Dimmed unless given a color, as faint text can be hard to read on
+	// some light themes
+	helpStyle = renderer.NewStyle().Faint(true)
+	if theme.Help != "" {
+		helpStyle = renderer.NewStyle().Foreground(themeColor(theme.Help))
+	}
+}
+
+// themeColor converts a theme color to a lipgloss color; empty means the
+// terminal's default
+func themeColor(color string) lipgloss.TerminalColor {
+	if color == "" {
+		return lipgloss.NoColor{}
+	}
+	return lipgloss.Color(color)
+}